@@ -0,0 +1,318 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/admin"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// runConfig 显示当前生效的NAT配置
+func runConfig(ctx context.Context, client admin.NATAdminClient, out *printer, args []string) error {
+	if err := parseFlags("config", args, nil); err != nil {
+		return err
+	}
+	resp, err := client.GetConfig(ctx, &admin.GetConfigRequest{})
+	if err != nil {
+		return err
+	}
+	cfg, err := resp.NATConfig()
+	if err != nil {
+		return err
+	}
+	return out.print(cfg, func(tw *tabwriter.Writer) {
+		configTable(tw, cfg)
+	})
+}
+
+// runSessions 列出NAT会话,自动翻页直到取完或达到-limit
+func runSessions(ctx context.Context, client admin.NATAdminClient, out *printer, args []string) error {
+	filter := &admin.SessionFilter{}
+	var limit int
+	if err := parseFlags("sessions", args, func(fs *flag.FlagSet) {
+		filterFlags(fs, filter)
+		fs.IntVar(&limit, "limit", 0, "maximum number of sessions to show (0 shows all)")
+	}); err != nil {
+		return err
+	}
+
+	resp := &admin.ListSessionsResponse{Sessions: []*admin.Session{}}
+	req := &admin.ListSessionsRequest{Filter: filter, PageSize: admin.MaxPageSize}
+	for {
+		page, err := client.ListSessions(ctx, req)
+		if err != nil {
+			return err
+		}
+		resp.Sessions = append(resp.Sessions, page.Sessions...)
+		resp.Total = page.Total
+		if page.NextPageToken == "" || (limit > 0 && len(resp.Sessions) >= limit) {
+			break
+		}
+		req.PageToken = page.NextPageToken
+	}
+	if limit > 0 && len(resp.Sessions) > limit {
+		resp.Sessions = resp.Sessions[:limit]
+	}
+
+	return out.print(resp, func(tw *tabwriter.Writer) {
+		row(tw, sessionHeader...)
+		for _, s := range resp.Sessions {
+			sessionRow(tw, s)
+		}
+		fmt.Fprintf(tw, "\n%d of %d sessions\n", len(resp.Sessions), resp.Total)
+	})
+}
+
+// runWatch 持续显示会话的新增和删除,直到中断
+func runWatch(ctx context.Context, client admin.NATAdminClient, out *printer, args []string) error {
+	req := &admin.WatchSessionsRequest{Filter: &admin.SessionFilter{}}
+	if err := parseFlags("watch", args, func(fs *flag.FlagSet) {
+		filterFlags(fs, req.Filter)
+		fs.Func("interval", "polling interval in seconds (default server side)", func(v string) error {
+			_, err := fmt.Sscan(v, &req.IntervalSeconds)
+			return err
+		})
+	}); err != nil {
+		return err
+	}
+
+	stream, err := client.WatchSessions(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		sign := "+"
+		if event.GetType() == admin.SessionEvent_REMOVED {
+			sign = "-"
+		}
+		s := event.GetSession()
+		line := fmt.Sprintf("%s %s %s:%d -> %s:%d ext %s:%d vrf %d",
+			sign, s.GetProtocol(), s.GetInsideIp(), s.GetInsidePort(), s.GetOutsideIp(), s.GetOutsidePort(), s.GetExtHostIp(), s.GetExtHostPort(), s.GetVrfId())
+		if s.GetConnectionId() != "" {
+			line += " conn " + s.GetConnectionId()
+		}
+		if err := out.printLine(event, line); err != nil {
+			return err
+		}
+	}
+}
+
+// runClear 清除满足条件的NAT会话
+func runClear(ctx context.Context, client admin.NATAdminClient, out *printer, args []string) error {
+	req := &admin.ClearSessionsRequest{Filter: &admin.SessionFilter{}}
+	if err := parseFlags("clear", args, func(fs *flag.FlagSet) {
+		filterFlags(fs, req.Filter)
+		fs.BoolVar(&req.FlushUser, "flush-user", false, "clear all sessions of the inside addresses that match the filter")
+	}); err != nil {
+		return err
+	}
+	if req.Filter.IsEmpty() {
+		return errors.New("clear requires at least one filter flag")
+	}
+
+	resp, err := client.ClearSessions(ctx, req)
+	if err != nil {
+		return err
+	}
+	return out.print(resp, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "removed %d sessions\n", resp.Removed)
+	})
+}
+
+// runPools 显示地址池端口使用情况
+func runPools(ctx context.Context, client admin.NATAdminClient, out *printer, args []string) error {
+	if err := parseFlags("pools", args, nil); err != nil {
+		return err
+	}
+	resp, err := client.GetPoolUsage(ctx, &admin.GetPoolUsageRequest{})
+	if err != nil {
+		return err
+	}
+	return out.print(resp, func(tw *tabwriter.Writer) {
+		row(tw, "POOL", "ADDRESS", "USED", "TOTAL", "UTIL")
+		for _, usage := range resp.Addresses {
+			util := 0.0
+			if usage.TotalPorts > 0 {
+				util = float64(usage.UsedPorts) * 100 / float64(usage.TotalPorts)
+			}
+			row(tw, usage.Pool, usage.Address, usage.UsedPorts, usage.TotalPorts, fmt.Sprintf("%.1f%%", util))
+		}
+	})
+}
+
+// runDNAT 运行时添加或删除DNAT静态映射
+func runDNAT(ctx context.Context, client admin.NATAdminClient, out *printer, args []string) error {
+	if len(args) == 0 || (args[0] != "add" && args[0] != "del") {
+		return errors.New("usage: natctl dnat add|del -external-ip IP [-external-port PORT [-external-port-end PORT]] -internal-ip IP [-internal-port PORT [-internal-port-end PORT]] [-protocol tcp|udp|both] [-twice-nat|-self-twice-nat]")
+	}
+	action := args[0]
+
+	var rule config.DNATRule
+	if err := parseFlags("dnat "+action, args[1:], func(fs *flag.FlagSet) {
+		fs.StringVar(&rule.ExternalIP, "external-ip", "", "external IP address")
+		uint16Var(fs, &rule.ExternalPort, "external-port", "external port, first port of a range (omit ports for an address-only rule)")
+		uint16Var(fs, &rule.ExternalPortEnd, "external-port-end", "last external port of a range")
+		fs.StringVar(&rule.InternalIP, "internal-ip", "", "internal IP address")
+		uint16Var(fs, &rule.InternalPort, "internal-port", "internal port, first port of a range")
+		uint16Var(fs, &rule.InternalPortEnd, "internal-port-end", "last internal port of a range, same size as the external range")
+		fs.StringVar(&rule.Protocol, "protocol", "", "protocol: tcp, udp or both (default tcp, omitted for address-only rules)")
+		fs.BoolVar(&rule.TwiceNAT, "twice-nat", false, "also translate the source address (requires twiceNATPool)")
+		fs.BoolVar(&rule.SelfTwiceNAT, "self-twice-nat", false, "translate the source only for hairpinned traffic (requires twiceNATPool)")
+	}); err != nil {
+		return err
+	}
+
+	if rule.Protocol == "" && !rule.IsAddressOnly() {
+		rule.Protocol = "tcp"
+	}
+
+	call := client.AddDNATRule
+	if action == "del" {
+		call = client.DelDNATRule
+	}
+	resp, err := call(ctx, &admin.DNATRuleRequest{Rule: admin.DNATRuleFromConfig(rule)})
+	if err != nil {
+		return err
+	}
+	rules, err := resp.ConfigRules()
+	if err != nil {
+		return err
+	}
+	return out.print(resp, func(tw *tabwriter.Writer) {
+		dnatTable(tw, rules)
+	})
+}
+
+// runLB 运行时添加或删除负载均衡DNAT后端
+func runLB(ctx context.Context, client admin.NATAdminClient, out *printer, args []string) error {
+	if len(args) == 0 || (args[0] != "add" && args[0] != "del") {
+		return errors.New("usage: natctl lb add|del -external-ip IP -external-port PORT [-protocol tcp|udp] -ip IP -port PORT [-weight N]")
+	}
+	action := args[0]
+
+	var rule config.LBRule
+	var backend config.LBBackend
+	if err := parseFlags("lb "+action, args[1:], func(fs *flag.FlagSet) {
+		fs.StringVar(&rule.ExternalIP, "external-ip", "", "external IP address of the load-balanced rule")
+		uint16Var(fs, &rule.ExternalPort, "external-port", "external port of the load-balanced rule")
+		fs.StringVar(&rule.Protocol, "protocol", "tcp", "protocol: tcp or udp")
+		fs.StringVar(&backend.IP, "ip", "", "backend IP address")
+		uint16Var(fs, &backend.Port, "port", "backend port")
+		fs.Func("weight", "backend weight 1-255 (add only, default 1)", func(v string) error {
+			_, err := fmt.Sscan(v, &backend.Weight)
+			return err
+		})
+	}); err != nil {
+		return err
+	}
+
+	call := client.AddLBBackend
+	if action == "del" {
+		call = client.DelLBBackend
+	}
+	resp, err := call(ctx, &admin.LBBackendRequest{
+		Rule:    admin.LBRuleFromConfig(rule),
+		Backend: admin.LBBackendFromConfig(backend),
+	})
+	if err != nil {
+		return err
+	}
+	rules, err := resp.ConfigRules()
+	if err != nil {
+		return err
+	}
+	return out.print(resp, func(tw *tabwriter.Writer) {
+		lbTable(tw, rules)
+	})
+}
+
+// runReload 重新加载NAT配置文件
+func runReload(ctx context.Context, client admin.NATAdminClient, out *printer, args []string) error {
+	if err := parseFlags("reload", args, nil); err != nil {
+		return err
+	}
+	resp, err := client.ReloadConfig(ctx, &admin.ReloadConfigRequest{})
+	if err != nil {
+		return err
+	}
+	return out.print(resp, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "NAT config reloaded")
+	})
+}
+
+// parseFlags 解析子命令参数,define为nil时子命令不接受任何参数
+func parseFlags(name string, args []string, define func(fs *flag.FlagSet)) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	if define != nil {
+		define(fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments for %s: %v", name, fs.Args())
+	}
+	return nil
+}
+
+// filterFlags 定义会话过滤参数
+func filterFlags(fs *flag.FlagSet, filter *admin.SessionFilter) {
+	fs.StringVar(&filter.InsideIp, "inside-ip", "", "inside IP address")
+	portVar(fs, &filter.InsidePort, "inside-port", "inside port")
+	fs.StringVar(&filter.OutsideIp, "outside-ip", "", "outside IP address")
+	portVar(fs, &filter.OutsidePort, "outside-port", "outside port")
+	fs.StringVar(&filter.Protocol, "protocol", "", "protocol: tcp, udp or icmp")
+	fs.StringVar(&filter.ConnectionId, "conn", "", "NSM connection ID")
+}
+
+// uint16Var 定义端口类参数
+func uint16Var(fs *flag.FlagSet, p *uint16, name, usage string) {
+	fs.Func(name, usage, func(v string) error {
+		_, err := fmt.Sscan(v, p)
+		return err
+	})
+}
+
+// portVar 定义消息中以uint32表示的端口参数,取值仍限制在uint16范围内
+func portVar(fs *flag.FlagSet, p *uint32, name, usage string) {
+	fs.Func(name, usage, func(v string) error {
+		var port uint16
+		if _, err := fmt.Sscan(v, &port); err != nil {
+			return err
+		}
+		*p = uint32(port)
+		return nil
+	})
+}
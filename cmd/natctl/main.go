@@ -0,0 +1,139 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// natctl 通过NSE的本地管理接口(NATAdmin)查看和调整NAT运行状态
+//
+// 用法:
+//
+//	natctl [-socket URL] [-o table|json] [-timeout D] <command> [flags]
+//
+// 命令:
+//
+//	config                 显示当前生效的NAT配置
+//	sessions [filter]      列出NAT会话
+//	watch [filter]         持续显示会话的新增和删除
+//	clear [filter]         清除NAT会话(-flush-user清除匹配用户的全部会话)
+//	pools                  显示地址池端口使用情况
+//	dnat add|del [rule]    运行时添加或删除DNAT静态映射
+//	lb add|del [backend]   运行时添加或删除负载均衡DNAT后端
+//	reload                 重新加载NAT配置文件
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/admin"
+)
+
+// defaultSocket 默认管理接口地址,与NSM_ADMIN_LISTEN_ON的默认值一致
+const defaultSocket = "unix:///var/run/nse-nat/admin.sock"
+
+// command natctl子命令
+type command struct {
+	usage string
+	run   func(ctx context.Context, client admin.NATAdminClient, out *printer, args []string) error
+}
+
+var commands = map[string]command{
+	"config":   {usage: "显示当前生效的NAT配置", run: runConfig},
+	"sessions": {usage: "列出NAT会话", run: runSessions},
+	"watch":    {usage: "持续显示会话的新增和删除", run: runWatch},
+	"clear":    {usage: "清除NAT会话", run: runClear},
+	"pools":    {usage: "显示地址池端口使用情况", run: runPools},
+	"dnat":     {usage: "运行时添加或删除DNAT静态映射(dnat add|del)", run: runDNAT},
+	"lb":       {usage: "运行时添加或删除负载均衡DNAT后端(lb add|del)", run: runLB},
+	"reload":   {usage: "重新加载NAT配置文件", run: runReload},
+}
+
+// commandOrder 帮助信息中子命令的显示顺序
+var commandOrder = []string{"config", "sessions", "watch", "clear", "pools", "dnat", "lb", "reload"}
+
+func main() {
+	socket := defaultSocket
+	if env := os.Getenv("NSM_ADMIN_LISTEN_ON"); env != "" {
+		socket = env
+	}
+
+	flags := flag.NewFlagSet("natctl", flag.ExitOnError)
+	flags.StringVar(&socket, "socket", socket, "NAT admin API address")
+	format := flags.String("o", formatTable, "output format: table or json")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout for a single request (not applied to watch)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: natctl [flags] <command> [command flags]\n\nCommands:\n")
+		for _, name := range commandOrder {
+			fmt.Fprintf(flags.Output(), "  %-10s %s\n", name, commands[name].usage)
+		}
+		fmt.Fprintf(flags.Output(), "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flags.Arg(0))
+		flags.Usage()
+		os.Exit(2)
+	}
+	out, err := newPrinter(os.Stdout, *format)
+	if err != nil {
+		fatal(err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if flags.Arg(0) != "watch" {
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	cc, err := grpc.NewClient(socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		fatal(err)
+	}
+	defer func() { _ = cc.Close() }()
+
+	if err := cmd.run(ctx, admin.NewNATAdminClient(cc), out, flags.Args()[1:]); err != nil {
+		fatal(err)
+	}
+}
+
+// fatal 打印错误并以非零状态退出
+func fatal(err error) {
+	if s, ok := status.FromError(err); ok {
+		fmt.Fprintf(os.Stderr, "natctl: %s: %s\n", s.Code(), s.Message())
+	} else {
+		fmt.Fprintf(os.Stderr, "natctl: %v\n", err)
+	}
+	os.Exit(1)
+}
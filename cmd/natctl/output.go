@@ -0,0 +1,250 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/admin"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// 输出格式
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// printer 按所选格式输出结果
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+// newPrinter 创建输出器,format为formatTable或formatJSON
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch format {
+	case formatTable:
+		return &printer{w: w}, nil
+	case formatJSON:
+		return &printer{w: w, json: true}, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format %q, expected %q or %q", format, formatTable, formatJSON)
+	}
+}
+
+// print JSON格式下输出v,表格格式下调用table
+func (p *printer) print(v interface{}, table func(tw *tabwriter.Writer)) error {
+	if p.json {
+		return p.encode(v, "  ")
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	table(tw)
+	return tw.Flush()
+}
+
+// printLine JSON格式下输出单行紧凑的v(用于watch),表格格式下输出line
+func (p *printer) printLine(v interface{}, line string) error {
+	if p.json {
+		return p.encode(v, "")
+	}
+	_, err := fmt.Fprintln(p.w, line)
+	return err
+}
+
+// encode 输出v的JSON编码,indent为空时输出单行;protobuf消息按protojson编码
+func (p *printer) encode(v interface{}, indent string) error {
+	if m, ok := v.(proto.Message); ok {
+		data, err := protojson.MarshalOptions{Indent: indent}.Marshal(m)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(p.w, string(data))
+		return err
+	}
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", indent)
+	return enc.Encode(v)
+}
+
+// row 输出一行以制表符分隔的列
+func row(tw *tabwriter.Writer, cols ...interface{}) {
+	strs := make([]string, len(cols))
+	for i, col := range cols {
+		strs[i] = fmt.Sprint(col)
+	}
+	fmt.Fprintln(tw, strings.Join(strs, "\t"))
+}
+
+// sessionHeader 会话表格的表头
+var sessionHeader = []interface{}{"PROTO", "INSIDE", "OUTSIDE", "EXT HOST", "VRF", "STATIC", "PKTS", "BYTES", "CONNECTION"}
+
+// sessionRow 输出一条会话
+func sessionRow(tw *tabwriter.Writer, s *admin.Session) {
+	row(tw, s.GetProtocol(),
+		fmt.Sprintf("%s:%d", s.GetInsideIp(), s.GetInsidePort()),
+		fmt.Sprintf("%s:%d", s.GetOutsideIp(), s.GetOutsidePort()),
+		fmt.Sprintf("%s:%d", s.GetExtHostIp(), s.GetExtHostPort()),
+		s.GetVrfId(), s.GetStatic(), s.GetTotalPkts(), s.GetTotalBytes(), orDash(s.GetConnectionId()))
+}
+
+// dnatTable 输出DNAT规则表格
+func dnatTable(tw *tabwriter.Writer, rules []config.DNATRule) {
+	row(tw, "PROTO", "EXTERNAL", "INTERNAL", "TWICE NAT")
+	for _, rule := range rules {
+		twiceNAT := "-"
+		switch {
+		case rule.TwiceNAT:
+			twiceNAT = "twice"
+		case rule.SelfTwiceNAT:
+			twiceNAT = "self"
+		}
+		if rule.IsAddressOnly() {
+			row(tw, "-", rule.ExternalIP, rule.InternalIP, twiceNAT)
+			continue
+		}
+		row(tw, strings.ToLower(rule.Protocol),
+			portSpan(rule.ExternalIP, rule.ExternalPort, rule.ExternalPortEnd),
+			portSpan(rule.InternalIP, rule.InternalPort, rule.InternalPortEnd), twiceNAT)
+	}
+}
+
+// portSpan 返回"ip:port",设置了结束端口时返回"ip:start-end"
+func portSpan(ip string, port, portEnd uint16) string {
+	if portEnd == 0 || portEnd == port {
+		return fmt.Sprintf("%s:%d", ip, port)
+	}
+	return fmt.Sprintf("%s:%d-%d", ip, port, portEnd)
+}
+
+// lbTable 输出负载均衡DNAT规则表格,每个后端一行
+func lbTable(tw *tabwriter.Writer, rules []config.LBRule) {
+	row(tw, "PROTO", "EXTERNAL", "BACKEND", "WEIGHT")
+	for _, rule := range rules {
+		for _, backend := range rule.Backends {
+			row(tw, strings.ToLower(rule.Protocol),
+				fmt.Sprintf("%s:%d", rule.ExternalIP, rule.ExternalPort),
+				fmt.Sprintf("%s:%d", backend.IP, backend.Port), backend.Weight)
+		}
+	}
+}
+
+// configTable 以分节表格输出NAT配置
+func configTable(tw *tabwriter.Writer, cfg *config.NATConfig) {
+	row(tw, "name:", orDash(cfg.Name))
+	row(tw, "natIP:", orDash(cfg.NatIP))
+	row(tw, "natPool:", orDash(strings.Join(cfg.NatPool, ", ")))
+	if len(cfg.TwiceNATPool) > 0 {
+		row(tw, "twiceNATPool:", strings.Join(cfg.TwiceNATPool, ", "))
+	}
+	if cfg.PortRange != nil {
+		row(tw, "portRange:", fmt.Sprintf("%d-%d", cfg.PortRange.Start, cfg.PortRange.End))
+	}
+	row(tw, "defaultAction:", orDash(cfg.DefaultAction))
+	if t := cfg.Timeouts; t != nil {
+		row(tw, "timeouts:", fmt.Sprintf("tcpEstablished=%ds tcpTransitory=%ds udp=%ds icmp=%ds",
+			t.TcpEstablished, t.TcpTransitory, t.Udp, t.Icmp))
+	}
+	if cfg.Hairpinning {
+		row(tw, "hairpinning:", "enabled")
+	}
+	if n := cfg.NAT64; n != nil {
+		row(tw, "nat64:", fmt.Sprintf("prefix=%s pool=%s", n.Prefix, strings.Join(n.Pool, ", ")))
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "SNAT rules:")
+	row(tw, "SRC NET", "ACTION")
+	for _, rule := range cfg.SnatRules {
+		row(tw, rule.SrcNet, rule.Action)
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "DNAT rules:")
+	dnatTable(tw, cfg.DnatRules)
+
+	if len(cfg.LBRules) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "Load-balanced DNAT rules:")
+		lbTable(tw, cfg.LBRules)
+	}
+
+	if len(cfg.Exemptions) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "Exemptions:")
+		row(tw, "PROTO", "ADDRESS")
+		for _, e := range cfg.Exemptions {
+			if e.Port == 0 {
+				row(tw, "-", e.IP)
+				continue
+			}
+			row(tw, strings.ToLower(e.Protocol), fmt.Sprintf("%s:%d", e.IP, e.Port))
+		}
+	}
+
+	if len(cfg.NPTv6) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "NPTv6 rules:")
+		row(tw, "INSIDE PREFIX", "OUTSIDE PREFIX")
+		for _, rule := range cfg.NPTv6 {
+			row(tw, rule.InsidePrefix, rule.OutsidePrefix)
+		}
+	}
+
+	if len(cfg.Pools) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "Pools:")
+		row(tw, "NAME", "ADDRESSES")
+		for _, pool := range cfg.Pools {
+			row(tw, pool.Name, strings.Join(pool.Addresses, ", "))
+		}
+	}
+
+	if len(cfg.PoolSelectors) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "Pool selectors:")
+		row(tw, "POOL", "MATCH LABELS", "SPIFFE ID")
+		for _, selector := range cfg.PoolSelectors {
+			var labels []string
+			for k, v := range selector.MatchLabels {
+				labels = append(labels, k+"="+v)
+			}
+			sort.Strings(labels)
+			row(tw, selector.Pool, orDash(strings.Join(labels, ",")), orDash(selector.SpiffeID))
+		}
+	}
+}
+
+// orDash 空字符串显示为"-"
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
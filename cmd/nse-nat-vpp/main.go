@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/edwarnicke/grpcfd"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	"google.golang.org/grpc"
@@ -43,20 +44,36 @@ import (
 	_ "github.com/networkservicemesh/cmd-nse-nat-vpp/internal/imports"
 
 	"github.com/networkservicemesh/cmd-nse-nat-vpp/internal/nat"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/admin"
 	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
 	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/lifecycle"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/metrics"
 	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/registry"
 	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/server"
 	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
 )
 
 func main() {
+	// 只校验NAT配置文件或输出Schema,不启动NSE
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case validateCommand:
+			os.Exit(runValidate(os.Args[2:], os.Stdout, os.Stderr))
+		case schemaCommand:
+			os.Exit(runSchema(os.Stdout))
+		}
+	}
+
 	// ********************************************************************************
 	// 设置上下文以捕获信号
 	// ********************************************************************************
 	ctx, cancel := lifecycle.NotifyContext()
 	defer cancel()
 
+	// 立即接管SIGHUP,否则启动期间收到的SIGHUP会按默认行为终止进程;
+	// 启动完成前收到的信号会在热更新器启动后触发一次重新加载
+	reloadCh := lifecycle.NotifyReload(ctx)
+
 	// ********************************************************************************
 	// 设置日志系统
 	// ********************************************************************************
@@ -86,8 +103,18 @@ func main() {
 
 	// 加载NAT配置
 	if err := cfg.LoadNATConfig(); err != nil {
+		var verrs config.ValidationErrors
+		if errors.As(err, &verrs) {
+			for _, fe := range verrs {
+				log.FromContext(ctx).Errorf("invalid NAT config: %v [%s]", fe, fe.Code)
+			}
+		}
 		logrus.Fatalf("failed to load NAT config: %v", err)
 	}
+	warnings, _ := config.CheckNATConfig(cfg.NATConfig)
+	for _, fe := range warnings {
+		log.FromContext(ctx).Warnf("NAT config: %v [%s]", fe, fe.Code)
+	}
 	log.FromContext(ctx).Infof("NAT config loaded: natIP=%s, snatRules=%d, dnatRules=%d",
 		cfg.NATConfig.NatIP, len(cfg.NATConfig.SnatRules), len(cfg.NATConfig.DnatRules))
 
@@ -170,20 +197,90 @@ func main() {
 	// 创建NAT配置器
 	// 使用vppConn (api.Connection) 直接创建，无需Channel
 	natConfigurator := vpp.NewNATConfigurator(vppConn)
+	natConfig := config.NewNATConfigHolder(cfg.NATConfig)
+	addressPool := vpp.NewAddressPool(natConfigurator)
+
+	// 下发与连接无关的NAT配置(DNAT静态映射等)
+	if err := nat.ApplyStaticConfig(ctx, cfg.NATConfig, natConfigurator); err != nil {
+		log.FromContext(ctx).Errorf("failed to apply NAT config: %v", err)
+	}
 
 	// 创建NAT端点
 	natEndpoint := nat.NewEndpoint(ctx, nat.Options{
 		Name:             cfg.Name,
 		ConnectTo:        &cfg.ConnectTo,
 		Labels:           cfg.Labels,
-		NATConfig:        cfg.NATConfig,
+		NATConfig:        natConfig,
 		NATConfigurator:  natConfigurator,
+		AddressPool:      addressPool,
 		MaxTokenLifetime: cfg.MaxTokenLifetime,
 		VPPConn:          vppConn,
 		Source:           source,
 		ClientOptions:    clientOptions,
 	})
 
+	// 启动时及周期性校准VPP中的NAT状态,修复漂移
+	reconciler := vpp.NewReconciler(natConfigurator, natEndpoint.DesiredState)
+	reconciler.Run(ctx, vpp.DefaultReconcileInterval)
+
+	// VPP重启或重连后会话超时和NAT64配置恢复为默认值,需重新下发
+	vpp.WatchRestart(ctx, vppConn, vpp.DefaultRestartCheckInterval, func() {
+		if err := natConfigurator.SetTimeouts(natConfig.Load().Timeouts); err != nil {
+			log.FromContext(ctx).Errorf("failed to re-apply NAT timeouts: %v", err)
+		}
+		if err := nat.ApplyNAT64(ctx, natConfig.Load(), natConfigurator); err != nil {
+			log.FromContext(ctx).Errorf("failed to re-apply NAT64 config: %v", err)
+		}
+		if err := reconciler.Reconcile(ctx); err != nil {
+			log.FromContext(ctx).Errorf("failed to reconcile NAT state: %v", err)
+		}
+	})
+
+	// 配置文件变化或收到SIGHUP时热更新NAT配置
+	reloader := nat.NewReloader(cfg.NATConfigPath, natConfig, natConfigurator, addressPool, reconciler)
+	reloadTriggers := []<-chan struct{}{reloadCh}
+	if fileChanges, err := config.WatchFile(ctx, cfg.NATConfigPath); err != nil {
+		log.FromContext(ctx).Warnf("failed to watch NAT config file, reload only on SIGHUP: %v", err)
+	} else {
+		reloadTriggers = append(reloadTriggers, fileChanges)
+	}
+	reloader.Run(ctx, reloadTriggers...)
+
+	// 启用Prometheus或OpenTelemetry时周期性采集NAT会话和转换统计;
+	// 管理接口按需读取地址池使用情况,不依赖周期采集
+	natMetrics := metrics.NewCollector(natConfigurator, natConfig)
+	if cfg.PrometheusEnabled || opentelemetry.IsEnabled() {
+		natMetrics.Run(ctx, cfg.MetricsExportInterval)
+	}
+	if opentelemetry.IsEnabled() {
+		if err := metrics.RegisterOTEL(natMetrics); err != nil {
+			log.FromContext(ctx).Errorf("failed to register NAT metrics: %v", err)
+		}
+	}
+	if cfg.PrometheusEnabled {
+		go metrics.ListenAndServe(ctx, cfg.PrometheusListenOn, natMetrics)
+	}
+
+	// 本地管理接口(natctl)
+	if cfg.AdminEnabled {
+		adminErrCh, err := admin.ListenAndServe(ctx, &cfg.AdminListenOn, admin.NewServer(admin.Options{
+			NATConfigurator: natConfigurator,
+			ConnectionID:    natEndpoint.ConnectionID,
+			NATConfig:       natConfig,
+			Metrics:         natMetrics,
+			ConfigManager:   reloader,
+		}))
+		if err != nil {
+			logrus.Fatalf("error starting admin server: %+v", err)
+		}
+		go func() {
+			if adminErr, ok := <-adminErrCh; ok && adminErr != nil {
+				log.FromContext(ctx).Errorf("admin server stopped: %v", adminErr)
+			}
+		}()
+		log.FromContext(ctx).Infof("admin server listening on %s", cfg.AdminListenOn.String())
+	}
+
 	// ********************************************************************************
 	log.FromContext(ctx).Infof("executing phase 5: create grpc server and register nat-server")
 	// ********************************************************************************
@@ -0,0 +1,167 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// 不启动NSE的子命令
+const (
+	// validateCommand 只校验NAT配置文件
+	validateCommand = "validate"
+
+	// schemaCommand 输出NAT配置文件的JSON Schema
+	schemaCommand = "schema"
+)
+
+// defaultNATConfigPath 与NSM_NAT_CONFIG_PATH的默认值一致
+const defaultNATConfigPath = "/etc/nat/config.yaml"
+
+// runValidate 加载并验证NAT配置文件,不启动VPP、不连接SPIRE
+//
+// 用于CI在发布前检查nat-config-file ConfigMap:
+// 将填充默认值后的配置输出到stdout,
+// 将全部验证错误和警告(字段路径、错误码)输出到stderr,
+// 警告(如DNAT端口落在SNAT端口范围内)不影响退出码。
+//
+// 用法:
+//
+//	nse-nat-vpp validate [-config PATH] [-o yaml|json]
+//
+// 返回:
+//   - int: 进程退出码,0表示配置有效,1表示配置无效,2表示参数错误
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	configPath := defaultNATConfigPath
+	if env := os.Getenv("NSM_NAT_CONFIG_PATH"); env != "" {
+		configPath = env
+	}
+
+	flags := flag.NewFlagSet(validateCommand, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&configPath, "config", configPath, "path to NAT config file")
+	format := flags.String("o", "yaml", "output format of the normalized config: yaml or json")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "yaml" && *format != "json" {
+		fmt.Fprintf(stderr, "unsupported output format %q\n", *format)
+		return 2
+	}
+
+	natCfg, err := config.LoadNATConfigFromFile(configPath)
+	if err != nil {
+		var verrs config.ValidationErrors
+		if !errors.As(err, &verrs) {
+			fmt.Fprintf(stderr, "%s: %v\n", configPath, err)
+			return 1
+		}
+		// 未知字段:配置无法可靠解析,只报告错误
+		printValidationErrors(stderr, configPath, verrs)
+		return 1
+	}
+
+	warnings, validateErr := config.CheckNATConfig(natCfg)
+	var verrs config.ValidationErrors
+	if validateErr != nil && !errors.As(validateErr, &verrs) {
+		verrs = config.ValidationErrors{{Message: validateErr.Error(), Severity: config.SeverityError}}
+	}
+
+	if err := printNATConfig(stdout, natCfg, verrs, warnings, *format); err != nil {
+		fmt.Fprintf(stderr, "failed to print NAT config: %v\n", err)
+		return 1
+	}
+
+	// 警告不影响退出码
+	if len(warnings) > 0 {
+		fmt.Fprintf(stderr, "%s: %d warnings:\n", configPath, len(warnings))
+		printFindings(stderr, warnings)
+	}
+	if len(verrs) > 0 {
+		printValidationErrors(stderr, configPath, verrs)
+		return 1
+	}
+
+	fmt.Fprintf(stderr, "%s: NAT configuration is valid\n", configPath)
+	return 0
+}
+
+// runSchema 输出NAT配置文件的JSON Schema
+//
+// 用法:
+//
+//	nse-nat-vpp schema > nat-config.schema.json
+func runSchema(stdout io.Writer) int {
+	if _, err := stdout.Write(config.NATConfigSchema); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// printValidationErrors 输出验证错误(字段路径、位置、错误码)
+func printValidationErrors(w io.Writer, configPath string, verrs config.ValidationErrors) {
+	fmt.Fprintf(w, "%s: invalid NAT configuration, %d errors:\n", configPath, len(verrs))
+	printFindings(w, verrs)
+}
+
+// printFindings 逐条输出验证错误或警告
+func printFindings(w io.Writer, findings config.ValidationErrors) {
+	for _, fe := range findings {
+		fmt.Fprintf(w, "  %v [%s]\n", fe, fe.Code)
+	}
+}
+
+// validateResult JSON格式的校验结果
+type validateResult struct {
+	Valid    bool                    `json:"valid"`
+	Config   *config.NATConfig       `json:"config"`
+	Errors   config.ValidationErrors `json:"errors,omitempty"`
+	Warnings config.ValidationErrors `json:"warnings,omitempty"`
+}
+
+// printNATConfig 输出NAT配置
+//
+// yaml格式只输出配置;json格式输出包含配置和全部验证错误、警告的对象,便于CI解析。
+func printNATConfig(w io.Writer, natCfg *config.NATConfig, verrs, warnings config.ValidationErrors, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(&validateResult{Valid: len(verrs) == 0, Config: natCfg, Errors: verrs, Warnings: warnings})
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(natCfg); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nat
+
+import (
+	"context"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// clientLabelsKey 在Server侧元数据中保存NSC请求标签的键
+type clientLabelsKey struct{}
+
+// storeClientLabels 保存NSC请求中的标签
+//
+// Client链中的passthrough会将请求标签替换为NSE自身的标签,
+// 因此需要在Server链中保存原始标签,供natClient选择地址池。
+func storeClientLabels(ctx context.Context, labels map[string]string) {
+	metadata.Map(ctx, false).Store(clientLabelsKey{}, labels)
+}
+
+// loadClientLabels 读取storeClientLabels保存的NSC请求标签
+func loadClientLabels(ctx context.Context) map[string]string {
+	if v, ok := metadata.Map(ctx, false).Load(clientLabelsKey{}); ok {
+		if labels, ok := v.(map[string]string); ok {
+			return labels
+		}
+	}
+	return nil
+}
+
+// poolKey 在Client侧元数据中记录连接所选NAT地址池名称的键
+type poolKey struct{}
+
+// storePool 在连接的Client侧元数据中记录所选NAT地址池名称
+//
+// 元数据只在本NSE内随连接保存,不会随请求发送给下游NSE。
+func storePool(ctx context.Context, pool string) {
+	metadata.Map(ctx, true).Store(poolKey{}, pool)
+}
+
+// deletePool 删除storePool记录的地址池名称
+func deletePool(ctx context.Context) {
+	metadata.Map(ctx, true).Delete(poolKey{})
+}
+
+// LoadPool 读取连接所选的NAT地址池名称
+//
+// 只有做SNAT的连接才有记录,未使用命名地址池时为config.DefaultPoolName。
+// 需在metadata.NewClient()之后的Client链中调用。
+func LoadPool(ctx context.Context) (string, bool) {
+	if v, ok := metadata.Map(ctx, true).Load(poolKey{}); ok {
+		if pool, ok := v.(string); ok {
+			return pool, true
+		}
+	}
+	return "", false
+}
+
+// snatDecisionKey 在Server侧元数据中保存连接SNAT动作的键
+type snatDecisionKey struct{}
+
+// snatDecision 按连接源地址匹配出的SNAT动作
+type snatDecision struct {
+	action string
+	err    error
+}
+
+// decideSNATAction 按连接的源地址匹配SNAT动作,并保存供Server链中的natServer使用
+//
+// 客户端地址由下游IPAM分配,Client链中的natClient在下游返回后最先拿到地址并做出决策,
+// natServer沿用同一决策,避免两侧在配置热更新时得出不同结论。
+func decideSNATAction(ctx context.Context, natConfig *config.NATConfig, conn *networkservice.Connection) (string, error) {
+	action, err := natConfig.MatchConnectionAction(connSourceIPs(conn))
+	metadata.Map(ctx, false).Store(snatDecisionKey{}, &snatDecision{action: action, err: err})
+	return action, err
+}
+
+// loadSNATAction 取出decideSNATAction保存的SNAT动作,没有保存时重新匹配
+//
+// 决策只对本次请求有效,取出后即删除,刷新请求会重新决策。
+func loadSNATAction(ctx context.Context, natConfig *config.NATConfig, conn *networkservice.Connection) (string, error) {
+	if v, ok := metadata.Map(ctx, false).LoadAndDelete(snatDecisionKey{}); ok {
+		if decision, ok := v.(*snatDecision); ok {
+			return decision.action, decision.err
+		}
+	}
+	return natConfig.MatchConnectionAction(connSourceIPs(conn))
+}
+
+// clientSpiffeID 从连接路径中提取NSC的SPIFFE ID
+//
+// 路径第一段由NSC生成,其token的sub声明即NSC的SPIFFE ID。
+// token已由authorize链节点验证,这里只解析不验证签名。
+func clientSpiffeID(conn *networkservice.Connection) string {
+	segments := conn.GetPath().GetPathSegments()
+	if len(segments) == 0 || segments[0].GetToken() == "" {
+		return ""
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(segments[0].GetToken(), claims); err != nil {
+		return ""
+	}
+	return claims.Subject
+}
@@ -22,6 +22,7 @@ package nat
 
 import (
 	"fmt"
+	"net"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/pkg/errors"
@@ -152,3 +153,28 @@ func (ip *InterfacePair) String() string {
 	return fmt.Sprintf("InterfacePair{ServerSide(inside): %d, ClientSide(outside): %d}",
 		ip.ServerSideIndex, ip.ClientSideIndex)
 }
+
+// connSourceIPs 返回连接IP上下文中的全部源地址
+//
+// SrcIpAddrs以CIDR形式存储(如"172.16.1.100/32"),无法解析的条目会被忽略。
+func connSourceIPs(conn *networkservice.Connection) []net.IP {
+	var ips []net.IP
+	for _, srcAddr := range conn.GetContext().GetIpContext().GetSrcIpAddrs() {
+		ip, _, err := net.ParseCIDR(srcAddr)
+		if err != nil {
+			continue
+		}
+		ips = append(ips, ip)
+	}
+	return ips
+}
+
+// hasIPv6Source 判断连接是否有IPv6源地址
+func hasIPv6Source(conn *networkservice.Connection) bool {
+	for _, ip := range connSourceIPs(conn) {
+		if ip.To4() == nil {
+			return true
+		}
+	}
+	return false
+}
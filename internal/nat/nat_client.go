@@ -29,6 +29,7 @@ import (
 	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 
@@ -46,16 +47,28 @@ import (
 //   - Client链: memif.NewClient() → NAT Client(配置outside和地址池)
 //
 // 职责:
-//   - 配置Client侧memif接口为NAT outside接口
-//   - 添加SNAT地址池
+//   - 下游返回客户端地址后按SnatRules决定连接的SNAT动作,并通过元数据交给natServer
+//   - 只为做SNAT的连接配置outside接口和引用地址池,直接转发或被拒绝的连接不改动VPP
+//   - 配置Client侧memif接口为NAT outside接口(配置了nat64时同时为NAT64 outside接口)
+//   - 在Client侧memif接口上添加NPTv6前缀映射
+//   - 按PoolSelectors为连接选择地址池,记录在连接的Client侧元数据中(见LoadPool,不会发送给下游NSE)
+//   - 通过地址池管理器引用SNAT地址(多个连接共享同一地址)
 //
 // 依赖:
 //   - 必须在memif.NewClient()之后执行
 //   - 使用ifindex.Load(ctx, true)加载Client侧接口索引
 type natClient struct {
-	natConfig       *config.NATConfig
+	natConfig       *config.NATConfigHolder
 	natConfigurator *vpp.NATConfigurator
-	configuredConns genericsync.Map[string, bool] // 跟踪已配置NAT的连接
+	addressPool     *vpp.AddressPool
+	configuredConns genericsync.Map[string, *natConn] // 连接ID → 已配置的NAT状态
+}
+
+// natConn 单个连接在Client侧的NAT状态
+type natConn struct {
+	swIfIndex uint32             // 已配置为outside的接口索引
+	nat64     bool               // 接口是否同时配置为NAT64 outside
+	nptv6     []config.NPTv6Rule // 接口上已添加的NPTv6前缀映射
 }
 
 // NewNATClient 创建NAT Client组件
@@ -64,8 +77,9 @@ type natClient struct {
 // 必须放置在memif.NewClient()之后,确保Client侧接口索引已存储到元数据。
 //
 // 参数:
-//   - natConfig: NAT配置(包含natIP等)
+//   - natConfig: 可热更新的NAT配置(包含natIP等)
 //   - natConfigurator: NAT配置器
+//   - addressPool: 按连接引用计数的地址池管理器
 //
 // 返回值:
 //   - networkservice.NetworkServiceClient: NSM Client链组件
@@ -74,20 +88,31 @@ type natClient struct {
 //
 //	client.WithAdditionalFunctionality(
 //	    memif.NewClient(ctx, vppConn),
-//	    NewNATClient(natConfig, natConfigurator),  // 在memif.NewClient之后
+//	    NewNATClient(natConfig, natConfigurator, addressPool),  // 在memif.NewClient之后
 //	    sendfd.NewClient(),
 //	    recvfd.NewClient(),
 //	)
-func NewNATClient(natConfig *config.NATConfig, natConfigurator *vpp.NATConfigurator) networkservice.NetworkServiceClient {
+func NewNATClient(natConfig *config.NATConfigHolder, natConfigurator *vpp.NATConfigurator, addressPool *vpp.AddressPool) networkservice.NetworkServiceClient {
+	return newNATClient(natConfig, natConfigurator, addressPool)
+}
+
+// newNATClient 创建NAT Client组件,返回具体类型供Endpoint读取连接状态
+func newNATClient(natConfig *config.NATConfigHolder, natConfigurator *vpp.NATConfigurator, addressPool *vpp.AddressPool) *natClient {
 	return &natClient{
 		natConfig:       natConfig,
 		natConfigurator: natConfigurator,
+		addressPool:     addressPool,
 	}
 }
 
 // Request Client端请求处理
 //
-// 配置NAT outside接口(Client侧memif)和SNAT地址池。
+// 先调用下一个Client链节点获取下游分配的客户端地址,再按SnatRules决定处理方式:
+//   - snat: 配置NAT outside接口(Client侧memif)并引用SNAT地址池
+//   - forward/drop或源地址动作不一致: 不做任何配置,由Server链中的natServer转发或拒绝连接
+//
+// 决策保存在元数据中供natServer沿用。配置失败时关闭已建立的下游连接并返回错误,
+// 下游请求失败时尚未做任何配置,无需回滚。
 // 使用ifindex.Load(ctx, true)从元数据加载Client侧接口索引。
 //
 // 参数:
@@ -101,46 +126,99 @@ func NewNATClient(natConfig *config.NATConfig, natConfigurator *vpp.NATConfigura
 func (nc *natClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
 	logger := log.FromContext(ctx).WithField("natClient", "Request")
 
-	// 检查此连接是否已配置NAT
-	connID := request.GetConnection().GetId()
-	_, alreadyConfigured := nc.configuredConns.Load(connID)
-	if alreadyConfigured {
-		logger.Infof("NAT已配置,跳过重复配置,连接ID: %s", connID)
-		return next.Client(ctx).Request(ctx, request, opts...)
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	// 步骤1: 调用下一个Client链节点,获取客户端地址
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
 	}
 
-	// 步骤1: 从元数据加载Client侧接口索引
-	logger.Info("从元数据加载Client侧接口索引")
+	// 刷新请求: outside接口和地址池已配置,无需重复配置
+	if _, ok := nc.configuredConns.Load(conn.GetId()); ok {
+		pool, _ := LoadPool(ctx)
+		logger.Infof("NAT已配置,跳过重复配置,连接ID: %s, 地址池: %s", conn.GetId(), pool)
+		return conn, nil
+	}
+
+	// 步骤2: 按源地址决定SNAT动作,只有做SNAT的连接需要outside接口和地址池
+	action, err := decideSNATAction(ctx, nc.natConfig.Load(), conn)
+	if err != nil || action != config.SNATActionSNAT {
+		return conn, nil
+	}
+
+	// 步骤3: 配置NAT outside接口和地址池
+	if err := nc.configure(ctx, conn); err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := next.Client(ctx).Close(closeCtx, conn, opts...); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// configure 为连接配置NAT outside接口并引用地址池,失败时移除本次已添加的配置
+func (nc *natClient) configure(ctx context.Context, conn *networkservice.Connection) error {
+	logger := log.FromContext(ctx).WithField("natClient", "configure")
+
+	// 从元数据加载Client侧接口索引
 	clientSideIfIndex, ok := ifindex.Load(ctx, true) // true = Client侧
 	if !ok {
-		return nil, errors.New("failed to load client side interface index from metadata")
+		return errors.New("failed to load client side interface index from metadata")
 	}
-	logger.Infof("加载Client侧接口索引: %d", clientSideIfIndex)
 
-	// 步骤2: 配置NAT outside接口
+	// 配置NAT outside接口
 	logger.Infof("配置NAT outside接口: %d", clientSideIfIndex)
 	if err := nc.natConfigurator.ConfigureOutsideInterface(uint32(clientSideIfIndex)); err != nil {
-		return nil, errors.Wrapf(err, "failed to configure NAT outside interface %d", clientSideIfIndex)
+		return errors.Wrapf(err, "failed to configure NAT outside interface %d", clientSideIfIndex)
 	}
 
-	// 步骤3: 添加SNAT地址池
-	logger.Infof("添加NAT地址池: %s", nc.natConfig.NatIP)
-	if err := nc.natConfigurator.AddNATAddressPool(nc.natConfig.NatIP); err != nil {
-		return nil, errors.Wrapf(err, "failed to add NAT address pool %s", nc.natConfig.NatIP)
+	// 配置了nat64时,IPv6客户端转换后的IPv4流量同样从该接口发出
+	configured := &natConn{swIfIndex: uint32(clientSideIfIndex)}
+	if nc.natConfig.Load().NAT64 != nil {
+		logger.Infof("配置NAT64 outside接口: %d", clientSideIfIndex)
+		if err := nc.natConfigurator.ConfigureNAT64OutsideInterface(uint32(clientSideIfIndex)); err != nil {
+			nc.removeOutside(ctx, configured)
+			return errors.Wrapf(err, "failed to configure NAT64 outside interface %d", clientSideIfIndex)
+		}
+		configured.nat64 = true
 	}
 
-	// 标记连接已配置NAT
-	nc.configuredConns.Store(connID, true)
+	// NPTv6前缀映射绑定在outside接口上,对进出该接口的IPv6流量做前缀替换
+	for _, rule := range nc.natConfig.Load().NPTv6 {
+		if err := nc.natConfigurator.AddNPTv6Binding(uint32(clientSideIfIndex), rule); err != nil {
+			nc.removeOutside(ctx, configured)
+			return errors.Wrapf(err, "failed to add NPTv6 binding on interface %d", clientSideIfIndex)
+		}
+		configured.nptv6 = append(configured.nptv6, rule)
+	}
 
-	logger.Info("NAT outside接口和地址池配置完成")
+	// 选择并引用SNAT地址池(首个使用者会将地址范围添加到VPP)
+	poolName, ranges, err := nc.selectPool(ctx, conn)
+	if err == nil {
+		logger.Infof("连接 %s 使用NAT地址池 %s: %v", conn.GetId(), poolName, ranges)
+		err = nc.addressPool.Acquire(conn.GetId(), ranges...)
+	}
+	if err != nil {
+		nc.removeOutside(ctx, configured)
+		return errors.Wrap(err, "failed to acquire NAT address pool")
+	}
+
+	// 在连接元数据中记录所选地址池,并标记连接已配置NAT
+	storePool(ctx, poolName)
+	nc.configuredConns.Store(conn.GetId(), configured)
 
-	// 步骤4: 调用下一个Client链节点
-	return next.Client(ctx).Request(ctx, request, opts...)
+	logger.Info("NAT outside接口和地址池配置完成")
+	return nil
 }
 
 // Close Client端关闭处理
 //
-// 清理NAT配置记录。
+// 清理连接在Client侧的NAT状态(见teardown),清理失败只记录告警,不影响连接关闭。
 //
 // 参数:
 //   - ctx: 请求上下文
@@ -151,14 +229,62 @@ func (nc *natClient) Request(ctx context.Context, request *networkservice.Networ
 //   - *empty.Empty: 空响应
 //   - error: 错误信息
 func (nc *natClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
-	logger := log.FromContext(ctx).WithField("natClient", "Close")
-
-	// 从已配置连接列表中移除
-	_, wasConfigured := nc.configuredConns.LoadAndDelete(conn.GetId())
-	if wasConfigured {
-		logger.Infof("清理NAT配置(Client侧),连接ID: %s", conn.GetId())
-	}
+	nc.teardown(ctx, conn.GetId())
 
 	// 调用下一个Client链节点
 	return next.Client(ctx).Close(ctx, conn, opts...)
 }
+
+// teardown 清理连接在Client侧的NAT状态
+//
+//   - 已配置NAT的连接: 移除Client侧接口上的NAT outside特性(及NAT64 outside特性、NPTv6映射)
+//   - 无论连接是否记录为已配置,都释放其地址引用,没有连接再使用时从VPP删除该地址;
+//     连接未持有引用时释放为空操作,因此失败的Request之后的Close是安全的
+func (nc *natClient) teardown(ctx context.Context, connID string) {
+	logger := log.FromContext(ctx).WithField("natClient", "teardown")
+
+	if configured, ok := nc.configuredConns.LoadAndDelete(connID); ok {
+		logger.Infof("清理NAT配置(Client侧),连接ID: %s", connID)
+		nc.removeOutside(ctx, configured)
+		deletePool(ctx)
+	}
+
+	if err := nc.addressPool.Release(connID); err != nil {
+		logger.Warnf("释放NAT地址失败: %v", err)
+	}
+}
+
+// removeOutside 移除接口上的NPTv6映射、NAT outside和NAT64 outside特性,失败只记录告警
+func (nc *natClient) removeOutside(ctx context.Context, configured *natConn) {
+	logger := log.FromContext(ctx).WithField("natClient", "removeOutside")
+
+	for _, rule := range configured.nptv6 {
+		if err := nc.natConfigurator.DelNPTv6Binding(configured.swIfIndex, rule); err != nil {
+			logger.Warnf("删除接口 %d 上的NPTv6映射 %s -> %s 失败: %v", configured.swIfIndex, rule.InsidePrefix, rule.OutsidePrefix, err)
+		}
+	}
+	if configured.nat64 {
+		if err := nc.natConfigurator.RemoveNAT64OutsideInterface(configured.swIfIndex); err != nil {
+			logger.Warnf("移除NAT64 outside接口 %d 失败: %v", configured.swIfIndex, err)
+		}
+	}
+	if err := nc.natConfigurator.RemoveOutsideInterface(configured.swIfIndex); err != nil {
+		logger.Warnf("移除NAT outside接口 %d 失败: %v", configured.swIfIndex, err)
+	}
+}
+
+// selectPool 为连接选择地址池
+//
+// 使用Server侧保存的NSC请求标签和路径中的NSC SPIFFE ID匹配PoolSelectors,
+// 未命中时使用默认地址池(natIP/natPool)。
+func (nc *natClient) selectPool(ctx context.Context, conn *networkservice.Connection) (string, []config.AddressRange, error) {
+	natConfig := nc.natConfig.Load()
+	pool := natConfig.SelectPool(loadClientLabels(ctx), clientSpiffeID(conn))
+	if pool == nil {
+		ranges, err := natConfig.PoolRanges()
+		return config.DefaultPoolName, ranges, err
+	}
+
+	ranges, err := pool.Ranges()
+	return pool.Name, ranges, err
+}
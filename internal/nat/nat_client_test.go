@@ -0,0 +1,239 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nat_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/checks/checkcontext"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/checks/checkcontextonreturn"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/stretchr/testify/require"
+	"go.fd.io/govpp/api"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/internal/nat"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
+)
+
+const clientSwIfIndex = 7
+
+// fakeConn 记录所有请求的VPP连接,所有请求都成功
+type fakeConn struct {
+	api.Connection
+
+	mu   sync.Mutex
+	reqs []api.Message
+}
+
+func (c *fakeConn) Invoke(_ context.Context, req, _ api.Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reqs = append(c.reqs, req)
+	return nil
+}
+
+// features 返回记录到的接口特性请求(IsAdd → 次数)
+func (c *fakeConn) features() map[bool]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[bool]int)
+	for _, req := range c.reqs {
+		if r, ok := req.(*nat44_ed.Nat44InterfaceAddDelFeature); ok {
+			counts[r.IsAdd]++
+		}
+	}
+	return counts
+}
+
+// addressRanges 返回记录到的地址池请求(IsAdd → 次数)
+func (c *fakeConn) addressRanges() map[bool]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[bool]int)
+	for _, req := range c.reqs {
+		if r, ok := req.(*nat44_ed.Nat44AddDelAddressRange); ok {
+			counts[r.IsAdd]++
+		}
+	}
+	return counts
+}
+
+func natIPRange(t *testing.T) config.AddressRange {
+	r, err := config.ParseAddressRange("203.0.113.10")
+	require.NoError(t, err)
+	return r
+}
+
+// newTestClient 构建 metadata → 存储Client侧接口索引 → natClient → next 的Client链
+func newTestClient(t *testing.T, conn *fakeConn, natConfig *config.NATConfig, next networkservice.NetworkServiceClient) (networkservice.NetworkServiceClient, *vpp.AddressPool) {
+	natConfigurator := vpp.NewNATConfigurator(conn)
+	pool := vpp.NewAddressPool(natConfigurator)
+
+	return chain.NewNetworkServiceClient(
+		metadata.NewClient(),
+		checkcontext.NewClient(t, func(_ *testing.T, ctx context.Context) {
+			ifindex.Store(ctx, true, interface_types.InterfaceIndex(clientSwIfIndex))
+		}),
+		nat.NewNATClient(config.NewNATConfigHolder(natConfig), natConfigurator, pool),
+		next,
+	), pool
+}
+
+func testNATConfig() *config.NATConfig {
+	return &config.NATConfig{
+		Name:      "nat-nse",
+		NatIP:     "203.0.113.10",
+		SnatRules: []config.SNATRule{{SrcNet: "10.0.0.0/8", Action: config.SNATActionSNAT}},
+	}
+}
+
+// testRequest 返回源地址为srcIPs的请求,下游原样返回请求中的连接
+func testRequest(srcIPs ...string) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "conn-1",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{SrcIpAddrs: srcIPs},
+			},
+		},
+	}
+}
+
+func TestNATClient_NextFailureLeavesNoState(t *testing.T) {
+	conn := &fakeConn{}
+	client, pool := newTestClient(t, conn, testNATConfig(), injecterror.NewClient())
+
+	_, err := client.Request(context.Background(), testRequest("10.0.0.1/32"))
+	require.Error(t, err)
+
+	require.Equal(t, 0, pool.Users(natIPRange(t)), "next失败后不应保留地址引用")
+	require.Empty(t, conn.addressRanges(), "next失败时不应向VPP添加地址")
+	require.Empty(t, conn.features(), "next失败时不应配置outside特性")
+}
+
+func TestNATClient_RetryAfterFailedRequestConfiguresAgain(t *testing.T) {
+	conn := &fakeConn{}
+	client, pool := newTestClient(t, conn, testNATConfig(), injecterror.NewClient(injecterror.WithRequestErrorTimes(0)))
+
+	_, err := client.Request(context.Background(), testRequest("10.0.0.1/32"))
+	require.Error(t, err)
+
+	_, err = client.Request(context.Background(), testRequest("10.0.0.1/32"))
+	require.NoError(t, err)
+	require.Equal(t, 1, pool.Users(natIPRange(t)))
+	require.Equal(t, map[bool]int{true: 1}, conn.features(), "重试时应配置outside特性")
+}
+
+func TestNATClient_CloseAfterFailedRequestAndRetry(t *testing.T) {
+	conn := &fakeConn{}
+	client, pool := newTestClient(t, conn, testNATConfig(), injecterror.NewClient(
+		injecterror.WithRequestErrorTimes(0),
+		injecterror.WithCloseErrorTimes(),
+	))
+
+	_, err := client.Request(context.Background(), testRequest("10.0.0.1/32"))
+	require.Error(t, err)
+
+	established, err := client.Request(context.Background(), testRequest("10.0.0.1/32"))
+	require.NoError(t, err)
+
+	_, err = client.Close(context.Background(), established)
+	require.NoError(t, err)
+
+	require.Equal(t, 0, pool.Users(natIPRange(t)))
+	require.Equal(t, map[bool]int{true: 1, false: 1}, conn.addressRanges(), "Close后应从VPP删除地址")
+	require.Equal(t, map[bool]int{true: 1, false: 1}, conn.features(), "Close后应移除outside特性")
+}
+
+func TestNATClient_CloseAfterFailedRequest(t *testing.T) {
+	conn := &fakeConn{}
+	client, pool := newTestClient(t, conn, testNATConfig(), injecterror.NewClient(injecterror.WithCloseErrorTimes()))
+
+	_, err := client.Request(context.Background(), testRequest("10.0.0.1/32"))
+	require.Error(t, err)
+
+	_, err = client.Close(context.Background(), testRequest().GetConnection())
+	require.NoError(t, err)
+
+	require.Equal(t, 0, pool.Users(natIPRange(t)))
+	require.Empty(t, conn.addressRanges())
+	require.Empty(t, conn.features())
+}
+
+func TestNATClient_ForwardedConnectionIsNotConfigured(t *testing.T) {
+	natConfig := testNATConfig()
+	natConfig.DefaultAction = config.SNATActionForward
+
+	conn := &fakeConn{}
+	client, pool := newTestClient(t, conn, natConfig, injecterror.NewClient(injecterror.WithRequestErrorTimes()))
+
+	_, err := client.Request(context.Background(), testRequest("192.168.1.1/32"))
+	require.NoError(t, err)
+
+	require.Equal(t, 0, pool.Users(natIPRange(t)), "直接转发的连接不应引用地址池")
+	require.Empty(t, conn.addressRanges())
+	require.Empty(t, conn.features(), "直接转发的连接不应配置outside特性")
+}
+
+func TestNATClient_RecordsPoolInMetadata(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		srcIP  string
+		pool   string
+		stored bool
+	}{
+		{name: "snat", srcIP: "10.0.0.1/32", pool: config.DefaultPoolName, stored: true},
+		{name: "forward", srcIP: "192.168.1.1/32"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			natConfig := testNATConfig()
+			natConfig.DefaultAction = config.SNATActionForward
+
+			natConfigurator := vpp.NewNATConfigurator(&fakeConn{})
+			client := chain.NewNetworkServiceClient(
+				metadata.NewClient(),
+				checkcontextonreturn.NewClient(t, func(t *testing.T, ctx context.Context) {
+					pool, ok := nat.LoadPool(ctx)
+					require.Equal(t, tc.stored, ok, "只有做SNAT的连接应记录地址池")
+					require.Equal(t, tc.pool, pool)
+				}),
+				checkcontext.NewClient(t, func(_ *testing.T, ctx context.Context) {
+					ifindex.Store(ctx, true, interface_types.InterfaceIndex(clientSwIfIndex))
+				}),
+				nat.NewNATClient(config.NewNATConfigHolder(natConfig), natConfigurator, vpp.NewAddressPool(natConfigurator)),
+			)
+
+			_, err := client.Request(context.Background(), testRequest(tc.srcIP))
+			require.NoError(t, err)
+		})
+	}
+}
@@ -23,13 +23,16 @@ package nat
 import (
 	"context"
 
+	"github.com/edwarnicke/genericsync"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
 	"github.com/pkg/errors"
 
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
 	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
 )
 
@@ -43,13 +46,23 @@ import (
 //   - Client链: memif.NewClient() → NAT Client
 //
 // 职责:
-//   - 配置Server侧memif接口为NAT inside接口
+//   - 按SnatRules匹配客户端的源地址(只匹配有规则的地址族),按连接决定SNAT、直接转发或拒绝
+//   - 需要SNAT时配置Server侧memif接口为NAT inside接口
+//   - 启用hairpinning时inside接口同时配置为NAT outside接口,使客户端可通过DNAT外部地址访问内部服务
+//   - 配置了nat64且客户端有IPv6地址时,同时配置为NAT64 inside接口
 //
 // 依赖:
 //   - 必须在memif.NewServer()之后执行
 //   - 使用ifindex.Load(ctx, false)加载Server侧接口索引
+//   - 客户端地址由下游IPAM分配,因此在next返回后才能做出决策;
+//     决策由Client链中的natClient做出并保存在元数据中,两侧据此一致地配置或跳过
 type natServer struct {
+	natConfig       *config.NATConfigHolder
 	natConfigurator *vpp.NATConfigurator
+	insideConns     genericsync.Map[string, uint32] // 连接ID → 已配置为inside的接口索引
+	insideAddrs     genericsync.Map[string, string] // 已做SNAT的客户端源地址 → 连接ID
+	hairpinConns    genericsync.Map[string, uint32] // 连接ID → 同时配置为outside(hairpinning)的inside接口索引
+	nat64Conns      genericsync.Map[string, uint32] // 连接ID → 已配置为NAT64 inside的接口索引
 }
 
 // NewNATServer 创建NAT Server组件
@@ -58,6 +71,7 @@ type natServer struct {
 // 必须放置在memif.NewServer()之后,确保Server侧接口索引已存储到元数据。
 //
 // 参数:
+//   - natConfig: 可热更新的NAT配置(包含snatRules和defaultAction)
 //   - natConfigurator: NAT配置器接口
 //
 // 返回值:
@@ -68,18 +82,29 @@ type natServer struct {
 //	mechanisms.NewServer(map[string]networkservice.NetworkServiceServer{
 //	    memif.MECHANISM: chain.NewNetworkServiceServer(
 //	        memif.NewServer(ctx, vppConn),
-//	        NewNATServer(natConfigurator),  // 在memif.NewServer之后
+//	        NewNATServer(natConfig, natConfigurator),  // 在memif.NewServer之后
 //	    ),
 //	}),
-func NewNATServer(natConfigurator *vpp.NATConfigurator) networkservice.NetworkServiceServer {
+func NewNATServer(natConfig *config.NATConfigHolder, natConfigurator *vpp.NATConfigurator) networkservice.NetworkServiceServer {
+	return newNATServer(natConfig, natConfigurator)
+}
+
+// newNATServer 创建NAT Server组件,返回具体类型供Endpoint读取连接状态
+func newNATServer(natConfig *config.NATConfigHolder, natConfigurator *vpp.NATConfigurator) *natServer {
 	return &natServer{
+		natConfig:       natConfig,
 		natConfigurator: natConfigurator,
 	}
 }
 
 // Request Server端请求处理
 //
-// 配置NAT inside接口(Server侧memif)。
+// 先调用下一个链节点获取下游分配的客户端地址,再按SnatRules决定处理方式。
+// 策略按连接生效,有规则的地址族中连接的全部源地址须命中同一动作,否则拒绝请求:
+//   - snat: 配置NAT inside接口(Server侧memif),接口上的全部流量都做转换
+//   - forward: 不配置inside接口,流量不做转换直接转发
+//   - drop: 关闭已建立的下游连接并拒绝请求(拒绝的是NSM连接,不在数据面丢包)
+//
 // 使用ifindex.Load(ctx, false)从元数据加载Server侧接口索引。
 //
 // 参数:
@@ -88,33 +113,99 @@ func NewNATServer(natConfigurator *vpp.NATConfigurator) networkservice.NetworkSe
 //
 // 返回值:
 //   - *networkservice.Connection: 连接对象
-//   - error: 错误信息(如接口索引加载失败或NAT配置失败)
+//   - error: 错误信息(如接口索引加载失败、NAT配置失败或源地址被拒绝)
 func (ns *natServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
 	logger := log.FromContext(ctx).WithField("natServer", "Request")
 
-	// 步骤1: 从元数据加载Server侧接口索引
-	logger.Info("从元数据加载Server侧接口索引")
-	serverSideIfIndex, ok := ifindex.Load(ctx, false) // false = Server侧
-	if !ok {
-		return nil, errors.New("failed to load server side interface index from metadata")
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	// 保存NSC请求标签,供Client链中的natClient选择地址池
+	storeClientLabels(ctx, request.GetConnection().GetLabels())
+
+	// 步骤1: 调用下一个Server链节点,获取客户端地址
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil {
+		return nil, err
 	}
-	logger.Infof("加载Server侧接口索引: %d", serverSideIfIndex)
 
-	// 步骤2: 配置NAT inside接口
-	logger.Infof("配置NAT inside接口: %d", serverSideIfIndex)
-	if err := ns.natConfigurator.ConfigureInsideInterface(uint32(serverSideIfIndex)); err != nil {
-		return nil, errors.Wrapf(err, "failed to configure NAT inside interface %d", serverSideIfIndex)
+	// 步骤2: 沿用natClient按源地址做出的SNAT决策(取出后即失效,刷新请求也需取出)
+	srcIPs := connSourceIPs(conn)
+	action, err := loadSNATAction(ctx, ns.natConfig.Load(), conn)
+
+	// 刷新请求: inside接口已配置,无需重复配置
+	if _, ok := ns.insideConns.Load(conn.GetId()); ok {
+		return conn, nil
 	}
+	if err == nil {
+		logger.Infof("客户端源地址 %v 匹配SNAT动作: %s", srcIPs, action)
+	}
+
+	switch {
+	case err != nil:
+		err = errors.Wrap(err, "connection rejected by NAT policy")
+	case action == config.SNATActionSNAT:
+		// 步骤3: 从元数据加载Server侧接口索引并配置NAT inside接口
+		serverSideIfIndex, ok := ifindex.Load(ctx, false) // false = Server侧
+		if !ok {
+			err = errors.New("failed to load server side interface index from metadata")
+			break
+		}
+		// hairpinning: inside接口同时作为outside接口,回环流量的响应在此做反向转换
+		hairpin := ns.natConfig.Load().Hairpinning
+		configure := ns.natConfigurator.ConfigureInsideInterface
+		if hairpin {
+			configure = ns.natConfigurator.ConfigureHairpinInterface
+		}
+		logger.Infof("配置NAT inside接口: %d, hairpinning: %v", serverSideIfIndex, hairpin)
+		if err = configure(uint32(serverSideIfIndex)); err != nil {
+			err = errors.Wrapf(err, "failed to configure NAT inside interface %d", serverSideIfIndex)
+			break
+		}
+		ns.insideConns.Store(conn.GetId(), uint32(serverSideIfIndex))
+		if hairpin {
+			ns.hairpinConns.Store(conn.GetId(), uint32(serverSideIfIndex))
+		}
+		for _, ip := range srcIPs {
+			ns.insideAddrs.Store(ip.String(), conn.GetId())
+		}
 
-	logger.Info("NAT inside接口配置完成")
+		// IPv6客户端通过NAT64访问IPv4网络
+		if ns.natConfig.Load().NAT64 != nil && hasIPv6Source(conn) {
+			logger.Infof("配置NAT64 inside接口: %d", serverSideIfIndex)
+			if err = ns.natConfigurator.ConfigureNAT64InsideInterface(uint32(serverSideIfIndex)); err != nil {
+				err = errors.Wrapf(err, "failed to configure NAT64 inside interface %d", serverSideIfIndex)
+				ns.removeInside(ctx, conn)
+				break
+			}
+			ns.nat64Conns.Store(conn.GetId(), uint32(serverSideIfIndex))
+		}
+		logger.Info("NAT inside接口配置完成")
+	case action == config.SNATActionDrop:
+		err = errors.Errorf("source addresses %v are not permitted by NAT policy", srcIPs)
+	default:
+		logger.Infof("源地址 %v 不做NAT转换,直接转发", srcIPs)
+	}
 
-	// 步骤3: 调用下一个Server链节点
-	return next.Server(ctx).Request(ctx, request)
+	if err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := next.Server(ctx).Close(closeCtx, conn); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+		return nil, err
+	}
+
+	return conn, nil
 }
 
 // Close Server端关闭处理
 //
-// 当前版本不执行NAT清理操作,直接传递到下一个链节点。
+// 对已配置SNAT的连接:
+//   - 移除Server侧接口上的NAT inside特性(及hairpinning的outside特性和NAT64 inside特性)
+//   - 清除客户端源地址的NAT会话
+//
+// 清理失败只记录告警,不影响连接关闭。
 //
 // 参数:
 //   - ctx: 请求上下文
@@ -124,5 +215,42 @@ func (ns *natServer) Request(ctx context.Context, request *networkservice.Networ
 //   - *empty.Empty: 空响应
 //   - error: 错误信息
 func (ns *natServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	ns.removeInside(ctx, conn)
+
 	return next.Server(ctx).Close(ctx, conn)
 }
+
+// removeInside 移除连接的NAT inside、hairpinning和NAT64 inside特性,并清除客户端的NAT44会话
+func (ns *natServer) removeInside(ctx context.Context, conn *networkservice.Connection) {
+	logger := log.FromContext(ctx).WithField("natServer", "removeInside")
+
+	if swIfIndex, ok := ns.nat64Conns.LoadAndDelete(conn.GetId()); ok {
+		logger.Infof("移除NAT64 inside接口: %d, 连接ID: %s", swIfIndex, conn.GetId())
+		if err := ns.natConfigurator.RemoveNAT64InsideInterface(swIfIndex); err != nil {
+			logger.Warnf("移除NAT64 inside接口失败: %v", err)
+		}
+	}
+
+	if swIfIndex, ok := ns.insideConns.LoadAndDelete(conn.GetId()); ok {
+		remove := ns.natConfigurator.RemoveInsideInterface
+		if _, hairpin := ns.hairpinConns.LoadAndDelete(conn.GetId()); hairpin {
+			remove = ns.natConfigurator.RemoveHairpinInterface
+		}
+		logger.Infof("移除NAT inside接口: %d, 连接ID: %s", swIfIndex, conn.GetId())
+		if err := remove(swIfIndex); err != nil {
+			logger.Warnf("移除NAT inside接口失败: %v", err)
+		}
+
+		for _, srcIP := range connSourceIPs(conn) {
+			if connID, ok := ns.insideAddrs.Load(srcIP.String()); ok && connID == conn.GetId() {
+				ns.insideAddrs.Delete(srcIP.String())
+			}
+			if srcIP.To4() == nil {
+				continue // NAT44会话只涉及IPv4地址
+			}
+			if err := ns.natConfigurator.ClearUserSessions(srcIP.String()); err != nil {
+				logger.Warnf("清除 %s 的NAT会话失败: %v", srcIP, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,581 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nat
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
+)
+
+// ErrDNATRuleNotFound 要删除的DNAT规则不在当前生效的配置中
+var ErrDNATRuleNotFound = errors.New("DNAT rule not found")
+
+// ErrLBRuleNotFound 要修改的负载均衡DNAT规则不在当前生效的配置中
+var ErrLBRuleNotFound = errors.New("load-balanced DNAT rule not found")
+
+// ErrLBBackendNotFound 要删除的后端不在负载均衡DNAT规则中
+var ErrLBBackendNotFound = errors.New("load-balanced DNAT backend not found")
+
+// Reloader NAT配置热更新器
+//
+// 重新读取并验证配置文件,与当前生效的配置做差异比较,
+// 只将发生变化的部分下发到VPP:
+//   - NAT会话超时
+//   - DNAT静态映射(删除移除的规则、添加新增的规则)
+//   - 负载均衡DNAT静态映射(外部端点不变时只增删或修改权重变化的后端,不影响其他后端上的会话;
+//     无法逐个修改后端权重时拒绝新配置)
+//   - NAT豁免(身份映射)
+//   - twice-NAT地址池
+//   - 正在使用中的地址池地址
+//   - NAT64前缀和地址池(删除nat64时停用nat64插件)
+//
+// SNAT规则、默认动作、地址池选择器、hairpinning、NAT64接口特性和NPTv6映射只影响之后建立的连接。
+// 新配置无效时保留当前配置不变。配置更新后触发一次状态校准。
+type Reloader struct {
+	configPath      string
+	natConfig       *config.NATConfigHolder
+	natConfigurator *vpp.NATConfigurator
+	addressPool     *vpp.AddressPool
+	reconciler      *vpp.Reconciler
+
+	mu sync.Mutex
+}
+
+// NewReloader 创建NAT配置热更新器
+//
+// 参数:
+//   - configPath: NAT配置文件路径
+//   - natConfig: 链节点共享的可热更新NAT配置
+//   - natConfigurator: NAT配置器
+//   - addressPool: 地址池管理器
+//   - reconciler: 配置更新后执行的状态校准器(可为nil)
+//
+// 示例:
+//
+//	reloader := nat.NewReloader(cfg.NATConfigPath, natConfig, natConfigurator, addressPool, reconciler)
+//	reloader.Run(ctx, lifecycle.NotifyReload(ctx), fileChanges)
+func NewReloader(configPath string, natConfig *config.NATConfigHolder, natConfigurator *vpp.NATConfigurator, addressPool *vpp.AddressPool, reconciler *vpp.Reconciler) *Reloader {
+	return &Reloader{
+		configPath:      configPath,
+		natConfig:       natConfig,
+		natConfigurator: natConfigurator,
+		addressPool:     addressPool,
+		reconciler:      reconciler,
+	}
+}
+
+// Run 在后台监听重新加载触发源
+//
+// 每个触发源收到通知时调用一次Reload;重新加载失败只记录错误。
+// ctx取消或触发源关闭时停止监听。
+//
+// 参数:
+//   - ctx: 上下文
+//   - triggers: 触发源,如lifecycle.NotifyReload和config.WatchFile返回的通道
+func (r *Reloader) Run(ctx context.Context, triggers ...<-chan struct{}) {
+	logger := log.FromContext(ctx).WithField("nat", "Reloader")
+
+	for _, trigger := range triggers {
+		go func(trigger <-chan struct{}) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case _, ok := <-trigger:
+					if !ok {
+						return
+					}
+					if err := r.Reload(ctx); err != nil {
+						logger.Errorf("NAT配置重新加载失败,继续使用当前配置: %v", err)
+					}
+				}
+			}
+		}(trigger)
+	}
+}
+
+// Reload 重新加载NAT配置
+//
+// 返回:
+//   - error: 新配置加载或验证失败(当前配置保持不变),或部分变更下发失败
+func (r *Reloader) Reload(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	logger := log.FromContext(ctx).WithField("nat", "Reload")
+
+	newCfg, err := config.LoadNATConfigFromFile(r.configPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load NAT config from %s", r.configPath)
+	}
+	warnings, err := config.CheckNATConfig(newCfg)
+	if err != nil {
+		return errors.Wrap(err, "invalid NAT configuration")
+	}
+
+	oldCfg := r.natConfig.Load()
+	if reflect.DeepEqual(oldCfg, newCfg) {
+		logger.Info("NAT配置未变化")
+		return nil
+	}
+
+	// 负载均衡后端权重只能逐个修改,无法修改时拒绝新配置,避免重建映射清除全部后端上的会话
+	_, _, changedLB := diffLBRules(oldCfg.LBRules, newCfg.LBRules)
+	for _, change := range changedLB {
+		if err := vpp.CheckLBBackendsUpdate(change.oldRule, change.newRule); err != nil {
+			return errors.Wrap(err, "NAT configuration rejected")
+		}
+	}
+
+	// 先切换配置,使新连接立即使用新的规则和地址池
+	r.natConfig.Store(newCfg)
+	logger.Infof("NAT配置已更新: natIP=%s, snatRules=%d, dnatRules=%d",
+		newCfg.NatIP, len(newCfg.SnatRules), len(newCfg.DnatRules))
+	for _, fe := range warnings {
+		logger.Warnf("NAT配置警告: %v [%s]", fe, fe.Code)
+	}
+
+	err = r.apply(ctx, oldCfg, newCfg)
+
+	// 校准修复下发失败或与连接处理并发导致的偏差
+	if r.reconciler != nil {
+		if reconcileErr := r.reconciler.Reconcile(ctx); reconcileErr != nil {
+			logger.Errorf("配置更新后NAT状态校准失败: %v", reconcileErr)
+		}
+	}
+
+	return err
+}
+
+// AddDNATRule 在运行时添加一条DNAT静态映射
+//
+// 规则加入当前生效的配置并下发到VPP,校准器因此会保留该映射。
+// 运行时添加的规则不写回配置文件,下次重新加载配置时会按文件内容被删除。
+//
+// 返回:
+//   - error: 加入规则后配置无效(如外部端点重复),或VPP下发失败(配置保持不变)
+func (r *Reloader) AddDNATRule(ctx context.Context, rule config.DNATRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	oldCfg := r.natConfig.Load()
+	newCfg := *oldCfg
+	newCfg.DnatRules = append(append([]config.DNATRule(nil), oldCfg.DnatRules...), rule)
+	if err := config.ValidateNATConfig(&newCfg); err != nil {
+		return errors.Wrap(err, "invalid DNAT rule")
+	}
+
+	if err := r.natConfigurator.AddStaticMapping(rule); err != nil {
+		return err
+	}
+	r.natConfig.Store(&newCfg)
+
+	log.FromContext(ctx).WithField("nat", "AddDNATRule").Infof("已添加DNAT规则 %s", rule)
+	return nil
+}
+
+// DelDNATRule 在运行时删除一条DNAT静态映射
+//
+// 规则从当前生效的配置中移除并从VPP删除,同样不写回配置文件。
+//
+// 返回:
+//   - error: 当前配置中没有该规则,或VPP删除失败(配置保持不变)
+func (r *Reloader) DelDNATRule(ctx context.Context, rule config.DNATRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	oldCfg := r.natConfig.Load()
+	newCfg := *oldCfg
+	newCfg.DnatRules = nil
+	found := false
+	for _, existing := range oldCfg.DnatRules {
+		if !found && existing.String() == rule.String() {
+			found = true
+			rule = existing // 按配置中的规则删除,保留twice-NAT标志
+			continue
+		}
+		newCfg.DnatRules = append(newCfg.DnatRules, existing)
+	}
+	if !found {
+		return errors.Wrapf(ErrDNATRuleNotFound, "%s", rule)
+	}
+
+	if err := r.natConfigurator.DelStaticMapping(rule); err != nil {
+		return err
+	}
+	r.natConfig.Store(&newCfg)
+
+	log.FromContext(ctx).WithField("nat", "DelDNATRule").Infof("已删除DNAT规则 %s", rule)
+	return nil
+}
+
+// AddLBBackend 在运行时向负载均衡DNAT规则添加一个后端
+//
+// rule只用于匹配当前配置中的规则(协议、externalIP和externalPort)。
+// 后端单独下发到VPP,其他后端上的已有会话不受影响。
+// 与AddDNATRule相同,变更不写回配置文件。
+//
+// 返回:
+//   - error: 规则不存在、加入后端后配置无效(如后端重复),或VPP下发失败(配置保持不变)
+func (r *Reloader) AddLBBackend(ctx context.Context, rule config.LBRule, backend config.LBBackend) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if backend.Weight == 0 {
+		backend.Weight = config.DefaultLBWeight
+	}
+
+	oldCfg := r.natConfig.Load()
+	i := findLBRule(oldCfg.LBRules, rule)
+	if i < 0 {
+		return errors.Wrap(ErrLBRuleNotFound, rule.Endpoint())
+	}
+	newCfg := *oldCfg
+	newCfg.LBRules = append([]config.LBRule(nil), oldCfg.LBRules...)
+	newCfg.LBRules[i].Backends = append(append([]config.LBBackend(nil), oldCfg.LBRules[i].Backends...), backend)
+	if err := config.ValidateNATConfig(&newCfg); err != nil {
+		return errors.Wrap(err, "invalid load-balanced DNAT backend")
+	}
+
+	if err := r.natConfigurator.AddLBBackend(newCfg.LBRules[i], backend); err != nil {
+		return err
+	}
+	r.natConfig.Store(&newCfg)
+
+	log.FromContext(ctx).WithField("nat", "AddLBBackend").Infof("已向负载均衡DNAT规则 %s 添加后端 %s:%d (权重%d)",
+		rule.Endpoint(), backend.IP, backend.Port, backend.Weight)
+	return nil
+}
+
+// DelLBBackend 在运行时从负载均衡DNAT规则删除一个后端
+//
+// 后端按地址和端口匹配,只有该后端上的会话被删除。规则至少保留2个后端。
+//
+// 返回:
+//   - error: 规则或后端不存在、删除后后端不足2个,或VPP删除失败(配置保持不变)
+func (r *Reloader) DelLBBackend(ctx context.Context, rule config.LBRule, backend config.LBBackend) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	oldCfg := r.natConfig.Load()
+	i := findLBRule(oldCfg.LBRules, rule)
+	if i < 0 {
+		return errors.Wrap(ErrLBRuleNotFound, rule.Endpoint())
+	}
+	j := oldCfg.LBRules[i].FindBackend(backend.IP, backend.Port)
+	if j < 0 {
+		return errors.Wrapf(ErrLBBackendNotFound, "%s:%d in %s", backend.IP, backend.Port, rule.Endpoint())
+	}
+	backends := oldCfg.LBRules[i].Backends
+	newCfg := *oldCfg
+	newCfg.LBRules = append([]config.LBRule(nil), oldCfg.LBRules...)
+	newCfg.LBRules[i].Backends = append(append([]config.LBBackend(nil), backends[:j]...), backends[j+1:]...)
+	if err := config.ValidateNATConfig(&newCfg); err != nil {
+		return errors.Wrap(err, "cannot remove load-balanced DNAT backend")
+	}
+
+	if err := r.natConfigurator.DelLBBackend(oldCfg.LBRules[i], backends[j]); err != nil {
+		return err
+	}
+	r.natConfig.Store(&newCfg)
+
+	log.FromContext(ctx).WithField("nat", "DelLBBackend").Infof("已从负载均衡DNAT规则 %s 删除后端 %s:%d",
+		rule.Endpoint(), backend.IP, backend.Port)
+	return nil
+}
+
+// apply 将新旧配置的差异下发到VPP
+func (r *Reloader) apply(ctx context.Context, oldCfg, newCfg *config.NATConfig) error {
+	logger := log.FromContext(ctx).WithField("nat", "Reload")
+	var failed int
+
+	if !reflect.DeepEqual(oldCfg.Timeouts, newCfg.Timeouts) {
+		if err := r.natConfigurator.SetTimeouts(newCfg.Timeouts); err != nil {
+			logger.Errorf("更新NAT会话超时失败: %v", err)
+			failed++
+		}
+	}
+
+	// 先添加新的twice-NAT地址,DNAT规则更新后再删除不再使用的地址
+	oldTwice, _ := oldCfg.TwiceNATRanges()
+	newTwice, _ := newCfg.TwiceNATRanges()
+	removedTwice, addedTwice := diffAddressRanges(oldTwice, newTwice)
+	for _, rng := range addedTwice {
+		if err := r.natConfigurator.AddTwiceNATAddressRange(rng); err != nil {
+			logger.Errorf("添加twice-NAT地址 %s 失败: %v", rng, err)
+			failed++
+		}
+	}
+
+	// 先删除两类映射中移除的规则,外部端点可能从DNAT规则转移到负载均衡规则或反之
+	removed, added := diffDNATRules(oldCfg.DnatRules, newCfg.DnatRules)
+	removedLB, addedLB, changedLB := diffLBRules(oldCfg.LBRules, newCfg.LBRules)
+	for _, ruleErr := range r.natConfigurator.DelDNATRules(removed) {
+		logger.Errorf("删除DNAT规则失败: %v", ruleErr)
+		failed++
+	}
+	for _, ruleErr := range r.natConfigurator.DelLBRules(removedLB) {
+		logger.Errorf("删除负载均衡DNAT规则失败: %v", ruleErr)
+		failed++
+	}
+	for _, ruleErr := range r.natConfigurator.AddDNATRules(added) {
+		logger.Errorf("添加DNAT规则失败: %v", ruleErr)
+		failed++
+	}
+	logger.Infof("DNAT静态映射更新: 删除%d条, 添加%d条", len(removed), len(added))
+
+	for _, change := range changedLB {
+		if err := r.natConfigurator.UpdateLBBackends(change.oldRule, change.newRule); err != nil {
+			logger.Errorf("更新负载均衡DNAT规则 %s 的后端失败: %v", change.newRule.Endpoint(), err)
+			failed++
+		}
+	}
+	for _, ruleErr := range r.natConfigurator.AddLBRules(addedLB) {
+		logger.Errorf("添加负载均衡DNAT规则失败: %v", ruleErr)
+		failed++
+	}
+	if len(removedLB) > 0 || len(addedLB) > 0 || len(changedLB) > 0 {
+		logger.Infof("负载均衡DNAT静态映射更新: 删除%d条, 添加%d条, 更新后端%d条", len(removedLB), len(addedLB), len(changedLB))
+	}
+
+	removedExemptions, addedExemptions := diffExemptions(oldCfg.Exemptions, newCfg.Exemptions)
+	for _, exemptionErr := range r.natConfigurator.DelExemptions(removedExemptions) {
+		logger.Errorf("删除NAT豁免失败: %v", exemptionErr)
+		failed++
+	}
+	for _, exemptionErr := range r.natConfigurator.AddExemptions(addedExemptions) {
+		logger.Errorf("添加NAT豁免失败: %v", exemptionErr)
+		failed++
+	}
+	if len(removedExemptions) > 0 || len(addedExemptions) > 0 {
+		logger.Infof("NAT豁免更新: 删除%d条, 添加%d条", len(removedExemptions), len(addedExemptions))
+	}
+
+	for _, rng := range removedTwice {
+		if err := r.natConfigurator.DelTwiceNATAddressRange(rng); err != nil {
+			logger.Errorf("删除twice-NAT地址 %s 失败: %v", rng, err)
+			failed++
+		}
+	}
+
+	for _, name := range poolNames(oldCfg, newCfg) {
+		oldRanges, _ := poolRanges(oldCfg, name)
+		newRanges, _ := poolRanges(newCfg, name)
+		if err := r.addressPool.Replace(oldRanges, newRanges); err != nil {
+			logger.Errorf("更新地址池 %s 失败: %v", name, err)
+			failed++
+		}
+	}
+
+	if !reflect.DeepEqual(oldCfg.NAT64, newCfg.NAT64) {
+		if err := r.applyNAT64(ctx, oldCfg, newCfg); err != nil {
+			logger.Errorf("更新NAT64配置失败: %v", err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return errors.Errorf("%d NAT config changes failed to apply", failed)
+	}
+	return nil
+}
+
+// applyNAT64 下发NAT64配置的变化
+//
+// 新增nat64时启用插件并下发全部配置;删除nat64时先删除前缀和地址池再停用插件;
+// 其余情况只替换变化的前缀和地址范围。
+func (r *Reloader) applyNAT64(ctx context.Context, oldCfg, newCfg *config.NATConfig) error {
+	if oldCfg.NAT64 == nil {
+		return ApplyNAT64(ctx, newCfg, r.natConfigurator)
+	}
+
+	oldRanges, _ := oldCfg.NAT64.Ranges()
+	var newRanges []config.AddressRange
+	newPrefix := ""
+	if newCfg.NAT64 != nil {
+		var err error
+		if newRanges, err = newCfg.NAT64.Ranges(); err != nil {
+			return err
+		}
+		newPrefix = newCfg.NAT64.Prefix
+	}
+
+	if oldCfg.NAT64.Prefix != newPrefix {
+		if err := r.natConfigurator.DelNAT64Prefix(oldCfg.NAT64.Prefix); err != nil {
+			return err
+		}
+	}
+	removed, added := diffAddressRanges(oldRanges, newRanges)
+	for _, rng := range removed {
+		if err := r.natConfigurator.DelNAT64AddressRange(rng); err != nil {
+			return err
+		}
+	}
+
+	if newCfg.NAT64 == nil {
+		return r.natConfigurator.DisableNAT64()
+	}
+
+	if oldCfg.NAT64.Prefix != newPrefix {
+		if err := r.natConfigurator.AddNAT64Prefix(newPrefix); err != nil {
+			return err
+		}
+	}
+	for _, rng := range added {
+		if err := r.natConfigurator.AddNAT64AddressRange(rng); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffAddressRanges 比较新旧地址范围,返回需要删除和需要添加的范围
+func diffAddressRanges(oldRanges, newRanges []config.AddressRange) (removed, added []config.AddressRange) {
+	oldSet := make(map[string]bool, len(oldRanges))
+	for _, rng := range oldRanges {
+		oldSet[rng.String()] = true
+	}
+	newSet := make(map[string]bool, len(newRanges))
+	for _, rng := range newRanges {
+		newSet[rng.String()] = true
+		if !oldSet[rng.String()] {
+			added = append(added, rng)
+		}
+	}
+	for _, rng := range oldRanges {
+		if !newSet[rng.String()] {
+			removed = append(removed, rng)
+		}
+	}
+	return removed, added
+}
+
+// diffDNATRules 比较新旧DNAT规则,返回需要删除和需要添加的规则
+func diffDNATRules(oldRules, newRules []config.DNATRule) (removed, added []config.DNATRule) {
+	oldSet := make(map[config.DNATRule]bool, len(oldRules))
+	for _, rule := range oldRules {
+		oldSet[rule] = true
+	}
+	newSet := make(map[config.DNATRule]bool, len(newRules))
+	for _, rule := range newRules {
+		newSet[rule] = true
+		if !oldSet[rule] {
+			added = append(added, rule)
+		}
+	}
+	for _, rule := range oldRules {
+		if !newSet[rule] {
+			removed = append(removed, rule)
+		}
+	}
+	return removed, added
+}
+
+// lbChange 外部端点相同但后端发生变化的负载均衡DNAT规则
+type lbChange struct {
+	oldRule config.LBRule
+	newRule config.LBRule
+}
+
+// diffLBRules 按外部端点比较新旧负载均衡DNAT规则,返回需要删除、需要添加和需要更新后端的规则
+func diffLBRules(oldRules, newRules []config.LBRule) (removed, added []config.LBRule, changed []lbChange) {
+	for _, rule := range newRules {
+		i := findLBRule(oldRules, rule)
+		switch {
+		case i < 0:
+			added = append(added, rule)
+		case !reflect.DeepEqual(oldRules[i].Backends, rule.Backends):
+			changed = append(changed, lbChange{oldRule: oldRules[i], newRule: rule})
+		}
+	}
+	for _, rule := range oldRules {
+		if findLBRule(newRules, rule) < 0 {
+			removed = append(removed, rule)
+		}
+	}
+	return removed, added, changed
+}
+
+// findLBRule 返回外部端点与rule相同的规则的下标,没有时返回-1
+func findLBRule(rules []config.LBRule, rule config.LBRule) int {
+	for i := range rules {
+		if rules[i].Endpoint() == rule.Endpoint() {
+			return i
+		}
+	}
+	return -1
+}
+
+// diffExemptions 比较新旧NAT豁免,返回需要删除和需要添加的豁免
+//
+// 按String()比较,协议大小写不同的豁免视为同一条。
+func diffExemptions(oldExemptions, newExemptions []config.Exemption) (removed, added []config.Exemption) {
+	oldSet := make(map[string]bool, len(oldExemptions))
+	for _, e := range oldExemptions {
+		oldSet[e.String()] = true
+	}
+	newSet := make(map[string]bool, len(newExemptions))
+	for _, e := range newExemptions {
+		newSet[e.String()] = true
+		if !oldSet[e.String()] {
+			added = append(added, e)
+		}
+	}
+	for _, e := range oldExemptions {
+		if !newSet[e.String()] {
+			removed = append(removed, e)
+		}
+	}
+	return removed, added
+}
+
+// poolNames 返回新旧配置中出现的全部地址池名称(包括默认地址池)
+func poolNames(cfgs ...*config.NATConfig) []string {
+	seen := map[string]bool{config.DefaultPoolName: true}
+	names := []string{config.DefaultPoolName}
+	for _, cfg := range cfgs {
+		for _, pool := range cfg.Pools {
+			if !seen[pool.Name] {
+				seen[pool.Name] = true
+				names = append(names, pool.Name)
+			}
+		}
+	}
+	return names
+}
+
+// poolRanges 返回配置中指定地址池的地址范围,地址池不存在时返回nil
+func poolRanges(cfg *config.NATConfig, name string) ([]config.AddressRange, error) {
+	if name == config.DefaultPoolName {
+		return cfg.PoolRanges()
+	}
+	if pool := cfg.FindPool(name); pool != nil {
+		return pool.Ranges()
+	}
+	return nil, nil
+}
@@ -52,6 +52,11 @@ import (
 // Endpoint NAT网络服务端点
 type Endpoint struct {
 	endpoint.Endpoint
+
+	natConfig   *config.NATConfigHolder
+	addressPool *vpp.AddressPool
+	natServer   *natServer
+	natClient   *natClient
 }
 
 // Options NAT端点配置选项
@@ -65,12 +70,15 @@ type Options struct {
 	// Labels 端点标签
 	Labels map[string]string
 
-	// NATConfig NAT配置
-	NATConfig *config.NATConfig
+	// NATConfig 可热更新的NAT配置
+	NATConfig *config.NATConfigHolder
 
 	// NATConfigurator VPP NAT配置器
 	NATConfigurator *vpp.NATConfigurator
 
+	// AddressPool 按连接引用计数的NAT地址池管理器
+	AddressPool *vpp.AddressPool
+
 	// MaxTokenLifetime token最大生命周期
 	MaxTokenLifetime time.Duration
 
@@ -106,15 +114,21 @@ type Options struct {
 //	    Name:             "nat-server",
 //	    ConnectTo:        &cfg.ConnectTo,
 //	    Labels:           cfg.Labels,
-//	    NATConfig:        natConfig,
+//	    NATConfig:        config.NewNATConfigHolder(natConfig),
 //	    NATConfigurator:  natCfg,
+//	    AddressPool:      vpp.NewAddressPool(natCfg),
 //	    MaxTokenLifetime: cfg.MaxTokenLifetime,
 //	    VPPConn:          vppConn,
 //	    Source:           source,
 //	    ClientOptions:    clientOptions,
 //	})
 func NewEndpoint(ctx context.Context, opts Options) *Endpoint {
-	ep := &Endpoint{}
+	ep := &Endpoint{
+		natConfig:   opts.NATConfig,
+		addressPool: opts.AddressPool,
+		natServer:   newNATServer(opts.NATConfig, opts.NATConfigurator),
+		natClient:   newNATClient(opts.NATConfig, opts.NATConfigurator, opts.AddressPool),
+	}
 
 	// 创建token生成器
 	tokenGenerator := spiffejwt.TokenGeneratorFunc(opts.Source, opts.MaxTokenLifetime)
@@ -141,7 +155,7 @@ func NewEndpoint(ctx context.Context, opts Options) *Endpoint {
 				memif.MECHANISM: chain.NewNetworkServiceServer(
 					memif.NewServer(ctx, opts.VPPConn),
 					// NAT Server配置inside接口（必须在memif.NewServer之后）
-					NewNATServer(opts.NATConfigurator),
+					ep.natServer,
 				),
 			}),
 			// 连接到下游服务
@@ -165,7 +179,7 @@ func NewEndpoint(ctx context.Context, opts Options) *Endpoint {
 						// Memif机制（客户端侧）
 						memif.NewClient(ctx, opts.VPPConn),
 						// NAT配置应用（必须在memif.NewClient之后，此时两侧接口索引都已存储到元数据）
-						NewNATClient(opts.NATConfig, opts.NATConfigurator),
+						ep.natClient,
 						// 发送文件描述符（客户端侧）
 						sendfd.NewClient(),
 						// 接收文件描述符（客户端侧）
@@ -0,0 +1,188 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nat
+
+import (
+	"context"
+	"strings"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
+)
+
+// ApplyStaticConfig 下发与连接无关的NAT配置
+//
+// 在NSE启动时(注册到NSM之前)调用,将不依赖具体连接的配置下发到VPP:
+//   - NAT会话超时(timeouts)
+//   - NAT64前缀和地址池(nat64)
+//   - twice-NAT地址池(twiceNATPool)
+//   - NAT豁免(exemptions,身份映射)
+//   - DNAT静态映射(dnatRules)
+//   - 负载均衡DNAT静态映射(lbRules)
+//
+// 各项配置相互独立:任一项(或单条NAT豁免、DNAT规则)下发失败时
+// 记录日志并继续处理其余配置,最终返回汇总错误。
+//
+// 参数:
+//   - ctx: 上下文,用于日志记录
+//   - natConfig: NAT配置
+//   - natConfigurator: NAT配置器
+//
+// 返回值:
+//   - error: 有规则配置失败时返回汇总错误
+//
+// 示例:
+//
+//	if err := nat.ApplyStaticConfig(ctx, cfg.NATConfig, natConfigurator); err != nil {
+//	    log.FromContext(ctx).Errorf("部分NAT配置下发失败: %v", err)
+//	}
+func ApplyStaticConfig(ctx context.Context, natConfig *config.NATConfig, natConfigurator *vpp.NATConfigurator) error {
+	logger := log.FromContext(ctx).WithField("nat", "ApplyStaticConfig")
+	var errs []error
+
+	// 下发会话超时(失败不影响其余配置的下发)
+	if err := natConfigurator.SetTimeouts(natConfig.Timeouts); err != nil {
+		logger.Errorf("NAT会话超时配置失败: %v", err)
+		errs = append(errs, errors.Wrap(err, "failed to set NAT timeouts"))
+	} else {
+		logger.Infof("NAT会话超时配置完成: tcpEstablished=%ds, tcpTransitory=%ds, udp=%ds, icmp=%ds",
+			natConfig.Timeouts.TcpEstablished, natConfig.Timeouts.TcpTransitory, natConfig.Timeouts.Udp, natConfig.Timeouts.Icmp)
+	}
+
+	// 下发NAT64前缀和地址池
+	if err := ApplyNAT64(ctx, natConfig, natConfigurator); err != nil {
+		logger.Errorf("NAT64配置失败: %v", err)
+		errs = append(errs, err)
+	}
+
+	// 下发twice-NAT地址池(启用twiceNAT的DNAT规则依赖它)
+	if err := applyTwiceNATPool(ctx, natConfig, natConfigurator); err != nil {
+		logger.Errorf("twice-NAT地址池配置失败: %v", err)
+		errs = append(errs, err)
+	}
+
+	// 下发NAT豁免(身份映射)
+	exemptionErrs := natConfigurator.AddExemptions(natConfig.Exemptions)
+	for _, exemptionErr := range exemptionErrs {
+		logger.Errorf("NAT豁免配置失败: %v", exemptionErr)
+	}
+	if len(natConfig.Exemptions) > 0 {
+		logger.Infof("NAT豁免配置完成: 成功%d条, 失败%d条", len(natConfig.Exemptions)-len(exemptionErrs), len(exemptionErrs))
+	}
+
+	// 下发DNAT静态映射
+	ruleErrs := natConfigurator.AddDNATRules(natConfig.DnatRules)
+	for _, ruleErr := range ruleErrs {
+		logger.Errorf("DNAT规则配置失败: %v", ruleErr)
+	}
+	logger.Infof("DNAT静态映射配置完成: 成功%d条, 失败%d条", len(natConfig.DnatRules)-len(ruleErrs), len(ruleErrs))
+
+	// 下发负载均衡DNAT静态映射
+	lbErrs := natConfigurator.AddLBRules(natConfig.LBRules)
+	for _, lbErr := range lbErrs {
+		logger.Errorf("负载均衡DNAT规则配置失败: %v", lbErr)
+	}
+	if len(natConfig.LBRules) > 0 {
+		logger.Infof("负载均衡DNAT静态映射配置完成: 成功%d条, 失败%d条", len(natConfig.LBRules)-len(lbErrs), len(lbErrs))
+	}
+
+	if len(ruleErrs) > 0 {
+		errs = append(errs, errors.Errorf("failed to configure %d of %d DNAT rules", len(ruleErrs), len(natConfig.DnatRules)))
+	}
+	if len(lbErrs) > 0 {
+		errs = append(errs, errors.Errorf("failed to configure %d of %d load-balanced DNAT rules", len(lbErrs), len(natConfig.LBRules)))
+	}
+	if len(exemptionErrs) > 0 {
+		errs = append(errs, errors.Errorf("failed to configure %d of %d exemptions", len(exemptionErrs), len(natConfig.Exemptions)))
+	}
+
+	return combineErrors(errs)
+}
+
+// applyTwiceNATPool 下发twice-NAT地址池
+func applyTwiceNATPool(ctx context.Context, natConfig *config.NATConfig, natConfigurator *vpp.NATConfigurator) error {
+	twiceRanges, err := natConfig.TwiceNATRanges()
+	if err != nil {
+		return err
+	}
+	for _, r := range twiceRanges {
+		if err := natConfigurator.AddTwiceNATAddressRange(r); err != nil {
+			return errors.Wrap(err, "failed to add twice-NAT address pool")
+		}
+	}
+	if len(twiceRanges) > 0 {
+		log.FromContext(ctx).WithField("nat", "ApplyStaticConfig").Infof("twice-NAT地址池配置完成: %v", twiceRanges)
+	}
+	return nil
+}
+
+// ApplyNAT64 启用nat64插件并下发NAT64前缀和地址池
+//
+// 未配置nat64时不做任何操作。启动时由ApplyStaticConfig调用,
+// VPP重启后插件状态丢失,需再次调用。
+//
+// 参数:
+//   - ctx: 上下文,用于日志记录
+//   - natConfig: NAT配置
+//   - natConfigurator: NAT配置器
+//
+// 返回值:
+//   - error: 插件启用、前缀或地址池下发失败
+func ApplyNAT64(ctx context.Context, natConfig *config.NATConfig, natConfigurator *vpp.NATConfigurator) error {
+	if natConfig.NAT64 == nil {
+		return nil
+	}
+
+	ranges, err := natConfig.NAT64.Ranges()
+	if err != nil {
+		return err
+	}
+
+	if err := natConfigurator.EnableNAT64(); err != nil {
+		return errors.Wrap(err, "failed to enable NAT64")
+	}
+	if err := natConfigurator.AddNAT64Prefix(natConfig.NAT64.Prefix); err != nil {
+		return errors.Wrap(err, "failed to add NAT64 prefix")
+	}
+	for _, r := range ranges {
+		if err := natConfigurator.AddNAT64AddressRange(r); err != nil {
+			return errors.Wrap(err, "failed to add NAT64 address pool")
+		}
+	}
+
+	log.FromContext(ctx).WithField("nat", "ApplyNAT64").Infof("NAT64配置完成: prefix=%s, pool=%v", natConfig.NAT64.Prefix, ranges)
+	return nil
+}
+
+// combineErrors 将多个下发错误合并为一个错误,没有错误时返回nil
+func combineErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return errors.Errorf("%d NAT config steps failed: %s", len(errs), strings.Join(msgs, "; "))
+}
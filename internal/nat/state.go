@@ -0,0 +1,80 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nat
+
+import (
+	"github.com/networkservicemesh/govpp/binapi/nat_types"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
+)
+
+// DesiredState 返回端点期望的nat44-ed状态
+//
+// 由当前NATConfig和活动连接构建,作为vpp.Reconciler的期望状态来源:
+//   - Addresses: 地址池管理器中正被连接使用的地址范围
+//   - TwiceNATAddresses: 配置中的twice-NAT地址池
+//   - Interfaces: 已配置的inside接口(Server侧,hairpinning时同时为outside)和outside接口(Client侧)
+//   - StaticMappings: 配置中的DNAT规则,端口范围和"both"协议展开为单条映射
+//   - LBMappings: 配置中的负载均衡DNAT规则
+//   - Exemptions: 配置中的NAT豁免
+//
+// 示例:
+//
+//	reconciler := vpp.NewReconciler(natCfg, natEndpoint.DesiredState)
+func (ep *Endpoint) DesiredState() *vpp.NATState {
+	state := &vpp.NATState{
+		Interfaces: make(map[uint32]nat_types.NatConfigFlags),
+		LBMappings: ep.natConfig.Load().LBRules,
+		Exemptions: ep.natConfig.Load().Exemptions,
+	}
+	for _, rule := range ep.natConfig.Load().DnatRules {
+		state.StaticMappings = append(state.StaticMappings, rule.Expand()...)
+	}
+	if ep.addressPool != nil {
+		state.Addresses = ep.addressPool.Ranges()
+	}
+	if twiceRanges, err := ep.natConfig.Load().TwiceNATRanges(); err == nil {
+		state.TwiceNATAddresses = twiceRanges
+	}
+
+	ep.natServer.insideConns.Range(func(_ string, swIfIndex uint32) bool {
+		state.Interfaces[swIfIndex] |= nat_types.NAT_IS_INSIDE
+		return true
+	})
+	ep.natServer.hairpinConns.Range(func(_ string, swIfIndex uint32) bool {
+		state.Interfaces[swIfIndex] |= nat_types.NAT_IS_OUTSIDE
+		return true
+	})
+	ep.natClient.configuredConns.Range(func(_ string, c *natConn) bool {
+		state.Interfaces[c.swIfIndex] |= nat_types.NAT_IS_OUTSIDE
+		return true
+	})
+
+	return state
+}
+
+// ConnectionID 返回使用指定内部地址做SNAT的NSM连接ID,未知时返回空字符串
+//
+// 供管理接口将VPP会话关联到NSM连接。
+func (ep *Endpoint) ConnectionID(insideIP string) string {
+	connID, _ := ep.natServer.insideAddrs.Load(insideIP)
+	return connID
+}
@@ -0,0 +1,24 @@
+// Package admin 提供NAT NSE的本地管理接口
+//
+// 本包定义NATAdmin gRPC服务及其客户端，供natctl等运维工具在不进入VPP的情况下
+// 查看和调整NAT运行状态。服务监听在Pod内的本地Unix socket上，不使用mTLS。
+//
+// 服务和消息定义见admin.proto，admin.pb.go和admin_grpc.pb.go由go generate
+// 调用protoc生成，不要手工修改。
+//
+// 主要功能：
+//   - 按条件列出NAT会话，支持分页
+//   - 流式推送会话的新增和删除
+//   - 按条件或按NAT用户清除会话
+//   - 查看当前生效的配置和地址池端口使用情况
+//   - 运行时添加/删除DNAT静态映射，触发配置重新加载
+//   - 运行时添加/删除负载均衡DNAT后端，不影响其他后端上的会话
+//
+// 使用示例：
+//
+//	srv := admin.NewServer(admin.Options{
+//	    NATConfigurator: natConfigurator,
+//	    ConnectionID:    natEndpoint.ConnectionID,
+//	})
+//	errCh, err := admin.ListenAndServe(ctx, &cfg.AdminListenOn, srv)
+package admin
@@ -0,0 +1,535 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/metrics"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
+)
+
+// 分页和订阅默认值
+const (
+	// DefaultPageSize ListSessions未指定PageSize时的每页会话数
+	DefaultPageSize = 100
+
+	// MaxPageSize ListSessions每页会话数上限
+	MaxPageSize = 1000
+
+	// DefaultWatchInterval WatchSessions未指定间隔时的轮询间隔
+	DefaultWatchInterval = 2 * time.Second
+)
+
+// ConfigManager 修改运行时NAT配置的组件(由nat.Reloader实现)
+type ConfigManager interface {
+	// Reload 重新加载NAT配置文件
+	Reload(ctx context.Context) error
+
+	// AddDNATRule 在运行时添加DNAT静态映射
+	AddDNATRule(ctx context.Context, rule config.DNATRule) error
+
+	// DelDNATRule 在运行时删除DNAT静态映射
+	DelDNATRule(ctx context.Context, rule config.DNATRule) error
+
+	// AddLBBackend 在运行时向负载均衡DNAT规则添加后端
+	AddLBBackend(ctx context.Context, rule config.LBRule, backend config.LBBackend) error
+
+	// DelLBBackend 在运行时从负载均衡DNAT规则删除后端
+	DelLBBackend(ctx context.Context, rule config.LBRule, backend config.LBBackend) error
+}
+
+// Options 管理服务配置选项
+type Options struct {
+	// NATConfigurator VPP NAT配置器
+	NATConfigurator *vpp.NATConfigurator
+
+	// ConnectionID 返回内部地址所属的NSM连接ID,未知时返回空字符串(可为nil)
+	ConnectionID func(insideIP string) string
+
+	// NATConfig 当前生效的NAT配置(为nil时GetConfig不可用)
+	NATConfig *config.NATConfigHolder
+
+	// Metrics NAT指标采集器,用于计算地址池使用情况(为nil时GetPoolUsage不可用)
+	Metrics *metrics.Collector
+
+	// ConfigManager 运行时配置修改(为nil时DNAT规则、负载均衡后端修改和ReloadConfig不可用)
+	ConfigManager ConfigManager
+}
+
+// Server NATAdmin服务实现
+type Server struct {
+	UnimplementedNATAdminServer
+
+	opts Options
+}
+
+// NewServer 创建NATAdmin服务
+//
+// 参数:
+//   - opts: 管理服务配置选项
+//
+// 返回:
+//   - *Server: 服务实例
+//
+// 示例:
+//
+//	srv := admin.NewServer(admin.Options{
+//	    NATConfigurator: natConfigurator,
+//	    ConnectionID:    natEndpoint.ConnectionID,
+//	    NATConfig:       natConfig,
+//	    Metrics:         natMetrics,
+//	    ConfigManager:   reloader,
+//	})
+func NewServer(opts Options) *Server {
+	return &Server{opts: opts}
+}
+
+// ListSessions 按条件分页列出NAT会话
+//
+// 会话按Key排序,PageToken为下一页起始位置。
+// 两次调用之间会话表可能变化,因此分页结果不保证无重复或无遗漏。
+func (s *Server) ListSessions(ctx context.Context, req *ListSessionsRequest) (*ListSessionsResponse, error) {
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+
+	offset := 0
+	if req.GetPageToken() != "" {
+		var err error
+		if offset, err = strconv.Atoi(req.GetPageToken()); err != nil || offset < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page token '%s'", req.GetPageToken())
+		}
+	}
+
+	sessions, err := s.sessions(ctx, req.GetFilter())
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	resp := &ListSessionsResponse{Sessions: []*Session{}, Total: int32(len(sessions))}
+	if offset >= len(sessions) {
+		return resp, nil
+	}
+	end := offset + pageSize
+	if end < len(sessions) {
+		resp.NextPageToken = strconv.Itoa(end)
+	} else {
+		end = len(sessions)
+	}
+	resp.Sessions = sessions[offset:end]
+
+	return resp, nil
+}
+
+// WatchSessions 持续推送满足条件的会话的新增和删除
+//
+// 订阅开始时先以ADDED事件推送全部现有会话,之后按间隔轮询VPP会话表,
+// 推送与上一次相比的差异,直到客户端取消。
+func (s *Server) WatchSessions(req *WatchSessionsRequest, stream NATAdmin_WatchSessionsServer) error {
+	ctx := stream.Context()
+
+	interval := DefaultWatchInterval
+	if req.GetIntervalSeconds() > 0 {
+		interval = time.Duration(req.GetIntervalSeconds()) * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	known := make(map[string]*Session)
+	for {
+		sessions, err := s.sessions(ctx, req.GetFilter())
+		if err != nil {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+
+		current := make(map[string]*Session, len(sessions))
+		for _, session := range sessions {
+			key := session.Key()
+			current[key] = session
+			if _, ok := known[key]; !ok {
+				if err := stream.Send(&SessionEvent{Type: SessionEvent_ADDED, Session: session}); err != nil {
+					return err
+				}
+			}
+		}
+		for key, session := range known {
+			if _, ok := current[key]; !ok {
+				if err := stream.Send(&SessionEvent{Type: SessionEvent_REMOVED, Session: session}); err != nil {
+					return err
+				}
+			}
+		}
+		known = current
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ClearSessions 删除满足条件的NAT会话
+//
+// 过滤条件不能为空,避免误清空整个会话表。FlushUser为true时,
+// 对匹配会话所属的每个内部地址使用Nat44DelUser一次清除其全部会话
+// (非默认VRF的用户逐条删除)。部分会话删除失败时返回Internal错误,
+// 已删除的会话不会恢复。
+func (s *Server) ClearSessions(ctx context.Context, req *ClearSessionsRequest) (*ClearSessionsResponse, error) {
+	if req.GetFilter().IsEmpty() {
+		return nil, status.Error(codes.InvalidArgument, "filter must not be empty")
+	}
+
+	sessions, err := s.sessions(ctx, req.GetFilter())
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	if req.GetFlushUser() {
+		users := make(map[string]bool)
+		for _, session := range sessions {
+			users[session.InsideIp] = true
+		}
+		if sessions, err = s.sessions(ctx, nil); err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		var userSessions []*Session
+		for _, session := range sessions {
+			if users[session.InsideIp] {
+				userSessions = append(userSessions, session)
+			}
+		}
+		sessions = userSessions
+	}
+
+	resp := &ClearSessionsResponse{}
+	flushed := make(map[string]bool) // 内部地址 → Nat44DelUser是否成功
+	var lastErr error
+	for _, session := range sessions {
+		if req.GetFlushUser() && session.VrfId == 0 {
+			ok, done := flushed[session.InsideIp]
+			if !done {
+				err := s.opts.NATConfigurator.ClearUserSessions(session.InsideIp)
+				if err != nil {
+					lastErr = err
+				}
+				ok = err == nil
+				flushed[session.InsideIp] = ok
+			}
+			if ok {
+				resp.Removed++
+			}
+			continue
+		}
+		if err := s.opts.NATConfigurator.DelSession(toVPPSession(session)); err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Removed++
+	}
+
+	if lastErr != nil {
+		return resp, status.Errorf(codes.Internal, "removed %d sessions: %s", resp.Removed, lastErr.Error())
+	}
+	return resp, nil
+}
+
+// GetConfig 返回当前生效的NAT配置
+//
+// 配置以JSON编码返回,字段名与配置文件相同,客户端用GetConfigResponse.NATConfig解码。
+func (s *Server) GetConfig(_ context.Context, _ *GetConfigRequest) (*GetConfigResponse, error) {
+	if s.opts.NATConfig == nil {
+		return nil, status.Error(codes.Unimplemented, "NAT config is not available")
+	}
+	data, err := json.Marshal(s.opts.NATConfig.Load())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &GetConfigResponse{ConfigJson: data}, nil
+}
+
+// GetPoolUsage 返回地址池中每个出口地址的端口使用情况
+//
+// 每次调用都重新读取VPP会话表,不依赖metrics周期采集是否启用。
+func (s *Server) GetPoolUsage(ctx context.Context, _ *GetPoolUsageRequest) (*GetPoolUsageResponse, error) {
+	if s.opts.Metrics == nil {
+		return nil, status.Error(codes.Unimplemented, "NAT metrics are not available")
+	}
+	usages, err := s.opts.Metrics.AddressUsage(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	resp := &GetPoolUsageResponse{Addresses: make([]*PoolUsage, 0, len(usages))}
+	for _, usage := range usages {
+		resp.Addresses = append(resp.Addresses, &PoolUsage{
+			Pool:       usage.Pool,
+			Address:    usage.Address,
+			UsedPorts:  uint32(usage.Used),
+			TotalPorts: uint32(usage.Total),
+		})
+	}
+	return resp, nil
+}
+
+// AddDNATRule 在运行时添加DNAT静态映射
+//
+// 运行时添加的规则不写回配置文件,下次重新加载配置时会被丢弃。
+func (s *Server) AddDNATRule(ctx context.Context, req *DNATRuleRequest) (*DNATRulesResponse, error) {
+	if s.opts.ConfigManager == nil {
+		return nil, status.Error(codes.Unimplemented, "runtime config changes are not available")
+	}
+	rule, err := req.GetRule().ToConfig()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.opts.ConfigManager.AddDNATRule(ctx, rule); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return s.dnatRules(), nil
+}
+
+// DelDNATRule 在运行时删除DNAT静态映射
+func (s *Server) DelDNATRule(ctx context.Context, req *DNATRuleRequest) (*DNATRulesResponse, error) {
+	if s.opts.ConfigManager == nil {
+		return nil, status.Error(codes.Unimplemented, "runtime config changes are not available")
+	}
+	rule, err := req.GetRule().ToConfig()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.opts.ConfigManager.DelDNATRule(ctx, rule); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return s.dnatRules(), nil
+}
+
+// AddLBBackend 在运行时向负载均衡DNAT规则添加后端
+//
+// 其他后端上的已有会话不受影响。与AddDNATRule相同,变更不写回配置文件。
+func (s *Server) AddLBBackend(ctx context.Context, req *LBBackendRequest) (*LBRulesResponse, error) {
+	if s.opts.ConfigManager == nil {
+		return nil, status.Error(codes.Unimplemented, "runtime config changes are not available")
+	}
+	rule, backend, err := lbBackendRequest(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.opts.ConfigManager.AddLBBackend(ctx, rule, backend); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return s.lbRules(), nil
+}
+
+// DelLBBackend 在运行时从负载均衡DNAT规则删除后端
+func (s *Server) DelLBBackend(ctx context.Context, req *LBBackendRequest) (*LBRulesResponse, error) {
+	if s.opts.ConfigManager == nil {
+		return nil, status.Error(codes.Unimplemented, "runtime config changes are not available")
+	}
+	rule, backend, err := lbBackendRequest(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.opts.ConfigManager.DelLBBackend(ctx, rule, backend); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return s.lbRules(), nil
+}
+
+// ReloadConfig 重新加载NAT配置文件
+func (s *Server) ReloadConfig(ctx context.Context, _ *ReloadConfigRequest) (*ReloadConfigResponse, error) {
+	if s.opts.ConfigManager == nil {
+		return nil, status.Error(codes.Unimplemented, "runtime config changes are not available")
+	}
+	if err := s.opts.ConfigManager.Reload(ctx); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &ReloadConfigResponse{}, nil
+}
+
+// lbBackendRequest 将负载均衡后端请求转换为配置中的规则和后端
+func lbBackendRequest(req *LBBackendRequest) (config.LBRule, config.LBBackend, error) {
+	rule, err := req.GetRule().ToConfig()
+	if err != nil {
+		return config.LBRule{}, config.LBBackend{}, err
+	}
+	backend, err := req.GetBackend().ToConfig()
+	if err != nil {
+		return config.LBRule{}, config.LBBackend{}, err
+	}
+	return rule, backend, nil
+}
+
+// dnatRules 返回当前生效的DNAT规则
+func (s *Server) dnatRules() *DNATRulesResponse {
+	resp := &DNATRulesResponse{Rules: []*DNATRule{}}
+	if s.opts.NATConfig != nil {
+		for _, rule := range s.opts.NATConfig.Load().DnatRules {
+			resp.Rules = append(resp.Rules, DNATRuleFromConfig(rule))
+		}
+	}
+	return resp
+}
+
+// lbRules 返回当前生效的负载均衡DNAT规则
+func (s *Server) lbRules() *LBRulesResponse {
+	resp := &LBRulesResponse{Rules: []*LBRule{}}
+	if s.opts.NATConfig != nil {
+		for _, rule := range s.opts.NATConfig.Load().LBRules {
+			resp.Rules = append(resp.Rules, LBRuleFromConfig(rule))
+		}
+	}
+	return resp
+}
+
+// sessions 读取VPP会话表,补充连接ID,返回按Key排序的满足条件的会话
+func (s *Server) sessions(ctx context.Context, filter *SessionFilter) ([]*Session, error) {
+	vppSessions, err := s.opts.NATConfigurator.DumpSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*Session, 0, len(vppSessions))
+	for i := range vppSessions {
+		session := fromVPPSession(&vppSessions[i])
+		if s.opts.ConnectionID != nil {
+			session.ConnectionId = s.opts.ConnectionID(session.InsideIp)
+		}
+		if filter.Matches(session) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Key() < sessions[j].Key() })
+
+	return sessions, nil
+}
+
+// fromVPPSession 将VPP会话转换为管理接口的会话
+func fromVPPSession(s *vpp.Session) *Session {
+	return &Session{
+		InsideIp:    s.InsideIP,
+		InsidePort:  uint32(s.InsidePort),
+		OutsideIp:   s.OutsideIP,
+		OutsidePort: uint32(s.OutsidePort),
+		ExtHostIp:   s.ExtHostIP,
+		ExtHostPort: uint32(s.ExtHostPort),
+		Protocol:    s.Protocol,
+		VrfId:       s.VrfID,
+		Static:      s.Static,
+		TotalPkts:   s.TotalPkts,
+		TotalBytes:  s.TotalBytes,
+	}
+}
+
+// ListenAndServe 在本地unix socket上启动NATAdmin gRPC服务
+//
+// 管理接口可以修改NAT状态且没有认证,因此只接受unix socket,
+// 由文件权限限制访问:socket所在目录权限为0700,socket文件为0600。
+// 自动创建socket所在目录,并删除残留的socket文件。
+//
+// 参数:
+//   - ctx: 上下文,取消时停止服务
+//   - listenOn: 监听地址(如unix:///var/run/nse-nat/admin.sock)
+//   - srv: NATAdmin服务实现
+//
+// 返回:
+//   - <-chan error: 服务器错误通道
+//   - error: listenOn不是unix socket,或创建socket失败
+//
+// 示例:
+//
+//	errCh, err := admin.ListenAndServe(ctx, &cfg.AdminListenOn, srv)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func ListenAndServe(ctx context.Context, listenOn *url.URL, srv NATAdminServer) (<-chan error, error) {
+	if listenOn.Scheme != "unix" {
+		return nil, errors.Errorf("admin API must listen on a unix socket, got %s", listenOn.String())
+	}
+	if err := os.MkdirAll(filepath.Dir(listenOn.Path), 0o700); err != nil {
+		return nil, errors.Wrapf(err, "failed to create directory for admin socket %s", listenOn.Path)
+	}
+	if err := os.Remove(listenOn.Path); err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to remove stale admin socket %s", listenOn.Path)
+	}
+
+	ln, err := net.Listen("unix", listenOn.Path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to listen on admin socket %s", listenOn.Path)
+	}
+	if err := os.Chmod(listenOn.Path, 0o600); err != nil {
+		_ = ln.Close()
+		return nil, errors.Wrapf(err, "failed to restrict permissions of admin socket %s", listenOn.Path)
+	}
+
+	grpcServer := grpc.NewServer()
+	RegisterNATAdminServer(grpcServer, srv)
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		if err := grpcServer.Serve(ln); err != nil {
+			errCh <- err
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		grpcServer.Stop()
+	}()
+
+	return errCh, nil
+}
+
+// toVPPSession 将管理接口的会话转换为VPP会话
+//
+// 会话来自fromVPPSession,端口都在uint16范围内。
+func toVPPSession(s *Session) *vpp.Session {
+	return &vpp.Session{
+		InsideIP:    s.InsideIp,
+		InsidePort:  uint16(s.InsidePort),
+		OutsideIP:   s.OutsideIp,
+		OutsidePort: uint16(s.OutsidePort),
+		ExtHostIP:   s.ExtHostIp,
+		ExtHostPort: uint16(s.ExtHostPort),
+		Protocol:    s.Protocol,
+		VrfID:       s.VrfId,
+		Static:      s.Static,
+	}
+}
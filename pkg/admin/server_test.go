@@ -0,0 +1,286 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+	"github.com/networkservicemesh/govpp/binapi/memclnt"
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.fd.io/govpp/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/admin"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
+)
+
+// fakeSession VPP会话表中的一条会话
+type fakeSession struct {
+	insideIP   string
+	insidePort uint16
+	protocol   uint16
+}
+
+// fakeConn 按sessions回放会话表的VPP连接,fail为nil或返回nil时请求成功
+type fakeConn struct {
+	api.Connection
+
+	sessions []fakeSession
+	fail     func(req api.Message) error
+
+	mu   sync.Mutex
+	reqs []api.Message
+}
+
+func (c *fakeConn) Invoke(_ context.Context, req, _ api.Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reqs = append(c.reqs, req)
+	if c.fail != nil {
+		return c.fail(req)
+	}
+	return nil
+}
+
+func (c *fakeConn) NewStream(ctx context.Context, _ ...api.StreamOption) (api.Stream, error) {
+	return &fakeStream{ctx: ctx, conn: c}, nil
+}
+
+// fakeStream 按dump请求回放NAT用户和会话,收到ControlPing时结束
+type fakeStream struct {
+	ctx     context.Context
+	conn    *fakeConn
+	pending []api.Message
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func (s *fakeStream) SendMsg(msg api.Message) error {
+	switch req := msg.(type) {
+	case *memclnt.ControlPing:
+		s.pending = append(s.pending, &memclnt.ControlPingReply{})
+	case *nat44_ed.Nat44UserDump:
+		users := make(map[string]bool)
+		for _, session := range s.conn.sessions {
+			if !users[session.insideIP] {
+				users[session.insideIP] = true
+				s.pending = append(s.pending, &nat44_ed.Nat44UserDetails{IPAddress: ipv4(session.insideIP)})
+			}
+		}
+	case *nat44_ed.Nat44UserSessionV3Dump:
+		for _, session := range s.conn.sessions {
+			if ipv4(session.insideIP) != req.IPAddress {
+				continue
+			}
+			s.pending = append(s.pending, &nat44_ed.Nat44UserSessionV3Details{
+				InsideIPAddress:  ipv4(session.insideIP),
+				InsidePort:       session.insidePort,
+				OutsideIPAddress: ipv4("203.0.113.10"),
+				OutsidePort:      session.insidePort + 10000,
+				ExtHostAddress:   ipv4("198.51.100.1"),
+				ExtHostPort:      443,
+				Protocol:         session.protocol,
+			})
+		}
+	}
+	return nil
+}
+
+func (s *fakeStream) RecvMsg() (api.Message, error) {
+	if len(s.pending) == 0 {
+		return nil, io.EOF
+	}
+	msg := s.pending[0]
+	s.pending = s.pending[1:]
+	return msg, nil
+}
+
+func (s *fakeStream) Close() error { return nil }
+
+func ipv4(addr string) ip_types.IP4Address {
+	var ip ip_types.IP4Address
+	copy(ip[:], net.ParseIP(addr).To4())
+	return ip
+}
+
+// 协议号
+const (
+	protoTCP = 6
+	protoUDP = 17
+)
+
+func newServer(conn *fakeConn) *admin.Server {
+	conn.sessions = []fakeSession{
+		{insideIP: "10.0.1.5", insidePort: 1001, protocol: protoTCP},
+		{insideIP: "10.0.1.5", insidePort: 1002, protocol: protoUDP},
+		{insideIP: "10.0.1.6", insidePort: 1003, protocol: protoTCP},
+	}
+	return admin.NewServer(admin.Options{
+		NATConfigurator: vpp.NewNATConfigurator(conn),
+		ConnectionID: func(insideIP string) string {
+			return "conn-" + insideIP
+		},
+	})
+}
+
+func TestListSessions_Filter(t *testing.T) {
+	srv := newServer(&fakeConn{})
+
+	for _, tc := range []struct {
+		name   string
+		filter *admin.SessionFilter
+		ports  []uint32
+	}{
+		{name: "all", ports: []uint32{1001, 1002, 1003}},
+		{name: "protocol", filter: &admin.SessionFilter{Protocol: "TCP"}, ports: []uint32{1001, 1003}},
+		{name: "inside address", filter: &admin.SessionFilter{InsideIp: "10.0.1.5"}, ports: []uint32{1001, 1002}},
+		{name: "connection", filter: &admin.SessionFilter{ConnectionId: "conn-10.0.1.6"}, ports: []uint32{1003}},
+		{name: "no match", filter: &admin.SessionFilter{InsidePort: 9999}, ports: []uint32{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := srv.ListSessions(context.Background(), &admin.ListSessionsRequest{Filter: tc.filter})
+			require.NoError(t, err)
+			ports := []uint32{}
+			for _, session := range resp.Sessions {
+				ports = append(ports, session.InsidePort)
+			}
+			require.ElementsMatch(t, tc.ports, ports)
+			require.Equal(t, int32(len(tc.ports)), resp.Total)
+		})
+	}
+}
+
+func TestListSessions_Pagination(t *testing.T) {
+	srv := newServer(&fakeConn{})
+	ctx := context.Background()
+
+	var keys []string
+	req := &admin.ListSessionsRequest{PageSize: 2}
+	for page := 0; ; page++ {
+		require.Less(t, page, 3, "分页应在两页内结束")
+		resp, err := srv.ListSessions(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, int32(3), resp.Total)
+		for _, session := range resp.Sessions {
+			keys = append(keys, session.Key())
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+	require.Len(t, keys, 3)
+	require.IsIncreasing(t, keys, "会话应按Key排序且不重复")
+
+	resp, err := srv.ListSessions(ctx, &admin.ListSessionsRequest{PageToken: "10"})
+	require.NoError(t, err)
+	require.Empty(t, resp.Sessions, "超出范围的页应为空")
+
+	_, err = srv.ListSessions(ctx, &admin.ListSessionsRequest{PageToken: "-1"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestClearSessions_RequiresFilter(t *testing.T) {
+	conn := &fakeConn{}
+	srv := newServer(conn)
+
+	_, err := srv.ClearSessions(context.Background(), &admin.ClearSessionsRequest{Filter: &admin.SessionFilter{}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Empty(t, conn.reqs, "空过滤条件不应删除任何会话")
+}
+
+func TestClearSessions_CountsDeletedSessions(t *testing.T) {
+	conn := &fakeConn{fail: func(req api.Message) error {
+		if r, ok := req.(*nat44_ed.Nat44DelSession); ok && r.IPAddress == ipv4("10.0.1.6") {
+			return errors.New("session not found")
+		}
+		return nil
+	}}
+	srv := newServer(conn)
+
+	resp, err := srv.ClearSessions(context.Background(), &admin.ClearSessionsRequest{
+		Filter: &admin.SessionFilter{Protocol: "tcp"},
+	})
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.Equal(t, uint32(1), resp.Removed, "删除失败的会话不应计数")
+}
+
+func TestClearSessions_FlushUserCountsOnlyFlushedUsers(t *testing.T) {
+	conn := &fakeConn{fail: func(req api.Message) error {
+		if r, ok := req.(*nat44_ed.Nat44DelUser); ok && r.IPAddress == ipv4("10.0.1.6") {
+			return errors.New("user not found")
+		}
+		return nil
+	}}
+	srv := newServer(conn)
+
+	resp, err := srv.ClearSessions(context.Background(), &admin.ClearSessionsRequest{
+		Filter:    &admin.SessionFilter{Protocol: "tcp"},
+		FlushUser: true,
+	})
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.Equal(t, uint32(2), resp.Removed, "只计入Nat44DelUser成功的用户的全部会话")
+
+	var delUsers int
+	for _, req := range conn.reqs {
+		if _, ok := req.(*nat44_ed.Nat44DelUser); ok {
+			delUsers++
+		}
+	}
+	require.Equal(t, 2, delUsers, "每个用户只应清除一次")
+}
+
+// fakeConfigManager 记录运行时添加的DNAT规则
+type fakeConfigManager struct {
+	admin.ConfigManager
+
+	dnatRules []config.DNATRule
+}
+
+func (m *fakeConfigManager) AddDNATRule(_ context.Context, rule config.DNATRule) error {
+	m.dnatRules = append(m.dnatRules, rule)
+	return nil
+}
+
+func TestAddDNATRule_ConvertsPorts(t *testing.T) {
+	manager := &fakeConfigManager{}
+	srv := admin.NewServer(admin.Options{ConfigManager: manager})
+
+	_, err := srv.AddDNATRule(context.Background(), &admin.DNATRuleRequest{Rule: &admin.DNATRule{
+		ExternalIp: "203.0.113.10", ExternalPort: 70000, InternalIp: "10.0.1.5", InternalPort: 80, Protocol: "tcp",
+	}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err), "超出uint16的端口应被拒绝")
+	require.Empty(t, manager.dnatRules)
+
+	rule := config.DNATRule{ExternalIP: "203.0.113.10", ExternalPort: 8080, InternalIP: "10.0.1.5", InternalPort: 80, Protocol: "tcp"}
+	_, err = srv.AddDNATRule(context.Background(), &admin.DNATRuleRequest{Rule: admin.DNATRuleFromConfig(rule)})
+	require.NoError(t, err)
+	require.Equal(t, []config.DNATRule{rule}, manager.dnatRules)
+}
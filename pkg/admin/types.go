@@ -0,0 +1,200 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// Key 返回会话的唯一键(协议+内部端点+对端端点+VRF)
+func (s *Session) Key() string {
+	return fmt.Sprintf("%s %s:%d->%s:%d@vrf%d", s.GetProtocol(), s.GetInsideIp(), s.GetInsidePort(), s.GetExtHostIp(), s.GetExtHostPort(), s.GetVrfId())
+}
+
+// IsEmpty 判断是否未设置任何条件
+func (f *SessionFilter) IsEmpty() bool {
+	return f == nil || proto.Size(f) == 0
+}
+
+// Matches 判断会话是否满足过滤条件
+func (f *SessionFilter) Matches(s *Session) bool {
+	if f == nil {
+		return true
+	}
+	switch {
+	case f.InsideIp != "" && f.InsideIp != s.GetInsideIp(),
+		f.InsidePort != 0 && f.InsidePort != s.GetInsidePort(),
+		f.OutsideIp != "" && f.OutsideIp != s.GetOutsideIp(),
+		f.OutsidePort != 0 && f.OutsidePort != s.GetOutsidePort(),
+		f.Protocol != "" && !strings.EqualFold(f.Protocol, s.GetProtocol()),
+		f.ConnectionId != "" && f.ConnectionId != s.GetConnectionId():
+		return false
+	}
+	return true
+}
+
+// NATConfig 解码响应中的NAT配置
+func (r *GetConfigResponse) NATConfig() (*config.NATConfig, error) {
+	cfg := &config.NATConfig{}
+	if err := json.Unmarshal(r.GetConfigJson(), cfg); err != nil {
+		return nil, errors.Wrap(err, "failed to decode NAT config")
+	}
+	return cfg, nil
+}
+
+// DNATRuleFromConfig 将配置中的DNAT规则转换为管理接口消息
+func DNATRuleFromConfig(r config.DNATRule) *DNATRule {
+	return &DNATRule{
+		ExternalIp:      r.ExternalIP,
+		ExternalPort:    uint32(r.ExternalPort),
+		ExternalPortEnd: uint32(r.ExternalPortEnd),
+		InternalIp:      r.InternalIP,
+		InternalPort:    uint32(r.InternalPort),
+		InternalPortEnd: uint32(r.InternalPortEnd),
+		Protocol:        r.Protocol,
+		TwiceNat:        r.TwiceNAT,
+		SelfTwiceNat:    r.SelfTwiceNAT,
+	}
+}
+
+// ToConfig 将管理接口消息转换为配置中的DNAT规则,端口超出0-65535时返回错误
+func (r *DNATRule) ToConfig() (config.DNATRule, error) {
+	var ports [4]uint16
+	for i, port := range []uint32{r.GetExternalPort(), r.GetExternalPortEnd(), r.GetInternalPort(), r.GetInternalPortEnd()} {
+		var err error
+		if ports[i], err = toPort(port); err != nil {
+			return config.DNATRule{}, err
+		}
+	}
+	return config.DNATRule{
+		ExternalIP:      r.GetExternalIp(),
+		ExternalPort:    ports[0],
+		ExternalPortEnd: ports[1],
+		InternalIP:      r.GetInternalIp(),
+		InternalPort:    ports[2],
+		InternalPortEnd: ports[3],
+		Protocol:        r.GetProtocol(),
+		TwiceNAT:        r.GetTwiceNat(),
+		SelfTwiceNAT:    r.GetSelfTwiceNat(),
+	}, nil
+}
+
+// ConfigRules 返回响应中的DNAT规则
+func (r *DNATRulesResponse) ConfigRules() ([]config.DNATRule, error) {
+	rules := make([]config.DNATRule, 0, len(r.GetRules()))
+	for _, rule := range r.GetRules() {
+		cfgRule, err := rule.ToConfig()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, cfgRule)
+	}
+	return rules, nil
+}
+
+// LBRuleFromConfig 将配置中的负载均衡DNAT规则转换为管理接口消息
+func LBRuleFromConfig(r config.LBRule) *LBRule {
+	rule := &LBRule{
+		ExternalIp:   r.ExternalIP,
+		ExternalPort: uint32(r.ExternalPort),
+		Protocol:     r.Protocol,
+		Backends:     make([]*LBBackend, 0, len(r.Backends)),
+	}
+	for _, backend := range r.Backends {
+		rule.Backends = append(rule.Backends, LBBackendFromConfig(backend))
+	}
+	return rule
+}
+
+// ToConfig 将管理接口消息转换为配置中的负载均衡DNAT规则
+func (r *LBRule) ToConfig() (config.LBRule, error) {
+	port, err := toPort(r.GetExternalPort())
+	if err != nil {
+		return config.LBRule{}, err
+	}
+	rule := config.LBRule{
+		ExternalIP:   r.GetExternalIp(),
+		ExternalPort: port,
+		Protocol:     r.GetProtocol(),
+	}
+	for _, backend := range r.GetBackends() {
+		cfgBackend, err := backend.ToConfig()
+		if err != nil {
+			return config.LBRule{}, err
+		}
+		rule.Backends = append(rule.Backends, cfgBackend)
+	}
+	return rule, nil
+}
+
+// LBBackendFromConfig 将配置中的负载均衡后端转换为管理接口消息
+func LBBackendFromConfig(b config.LBBackend) *LBBackend {
+	return &LBBackend{
+		Ip:     b.IP,
+		Port:   uint32(b.Port),
+		Weight: uint32(b.Weight),
+	}
+}
+
+// ToConfig 将管理接口消息转换为配置中的负载均衡后端,权重超出0-255时返回错误
+func (b *LBBackend) ToConfig() (config.LBBackend, error) {
+	port, err := toPort(b.GetPort())
+	if err != nil {
+		return config.LBBackend{}, err
+	}
+	if b.GetWeight() > math.MaxUint8 {
+		return config.LBBackend{}, errors.Errorf("weight %d is out of range 1-255", b.GetWeight())
+	}
+	return config.LBBackend{
+		IP:     b.GetIp(),
+		Port:   port,
+		Weight: uint8(b.GetWeight()),
+	}, nil
+}
+
+// ConfigRules 返回响应中的负载均衡DNAT规则
+func (r *LBRulesResponse) ConfigRules() ([]config.LBRule, error) {
+	rules := make([]config.LBRule, 0, len(r.GetRules()))
+	for _, rule := range r.GetRules() {
+		cfgRule, err := rule.ToConfig()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, cfgRule)
+	}
+	return rules, nil
+}
+
+// toPort 将消息中的端口转换为uint16
+func toPort(port uint32) (uint16, error) {
+	if port > math.MaxUint16 {
+		return 0, errors.Errorf("port %d is out of range 0-65535", port)
+	}
+	return uint16(port), nil
+}
@@ -45,6 +45,10 @@ type Config struct {
 	MetricsExportInterval  time.Duration     `default:"10s" desc:"interval between mertics exports" split_words:"true"`
 	PprofEnabled           bool              `default:"false" desc:"is pprof enabled" split_words:"true"`
 	PprofListenOn          string            `default:"localhost:6060" desc:"pprof URL to ListenAndServe" split_words:"true"`
+	PrometheusEnabled      bool              `default:"false" desc:"is Prometheus NAT metrics endpoint enabled" split_words:"true"`
+	PrometheusListenOn     string            `default:"localhost:9090" desc:"Prometheus NAT metrics URL to ListenAndServe (use :9090 to expose it outside the pod)" split_words:"true"`
+	AdminEnabled           bool              `default:"false" desc:"is local NAT admin API (unix socket only, no auth) enabled" split_words:"true"`
+	AdminListenOn          url.URL           `default:"unix:///var/run/nse-nat/admin.sock" desc:"url the NAT admin API listens on" split_words:"true"`
 }
 
 // Load 从环境变量加载配置，返回配置实例
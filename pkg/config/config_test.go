@@ -43,20 +43,22 @@ func TestLoad_DefaultValues(t *testing.T) {
 	require.NotNil(t, cfg, "Config不应该为nil")
 
 	// 验证默认值
-	require.Equal(t, "firewall-server", cfg.Name)
+	require.Equal(t, "nat-server", cfg.Name)
 	require.Equal(t, "listen.on.sock", cfg.ListenOn)
 	require.Equal(t, "unix:///var/lib/networkservicemesh/nsm.io.sock", cfg.ConnectTo.String())
 	require.Equal(t, 10*time.Minute, cfg.MaxTokenLifetime)
 	require.Equal(t, "INFO", cfg.LogLevel)
-	require.Equal(t, "/etc/firewall/config.yaml", cfg.ACLConfigPath)
+	require.Equal(t, "/etc/nat/config.yaml", cfg.NATConfigPath)
 	require.Equal(t, 10*time.Second, cfg.MetricsExportInterval)
 	require.False(t, cfg.PprofEnabled)
 	require.Equal(t, "localhost:6060", cfg.PprofListenOn)
+	require.False(t, cfg.PrometheusEnabled)
+	require.Equal(t, "localhost:9090", cfg.PrometheusListenOn)
 }
 
 func TestLoad_CustomValues(t *testing.T) {
 	// 设置自定义环境变量
-	os.Setenv("NSM_NAME", "test-firewall")
+	os.Setenv("NSM_NAME", "test-nat")
 	os.Setenv("NSM_SERVICE_NAME", "test-service")
 	os.Setenv("NSM_LOG_LEVEL", "DEBUG")
 	os.Setenv("NSM_MAX_TOKEN_LIFETIME", "5m")
@@ -66,20 +68,24 @@ func TestLoad_CustomValues(t *testing.T) {
 	cfg, err := config.Load(ctx)
 
 	require.NoError(t, err)
-	require.Equal(t, "test-firewall", cfg.Name)
+	require.Equal(t, "test-nat", cfg.Name)
 	require.Equal(t, "test-service", cfg.ServiceName)
 	require.Equal(t, "DEBUG", cfg.LogLevel)
 	require.Equal(t, 5*time.Minute, cfg.MaxTokenLifetime)
 }
 
 func TestValidate_Success(t *testing.T) {
+	natCfg, err := config.ParseNATConfigFromYAML([]byte(validNATConfig))
+	require.NoError(t, err)
+
 	cfg := &config.Config{
 		Name:        "test-server",
 		ServiceName: "test-service",
 		ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		NATConfig:   natCfg,
 	}
 
-	err := cfg.Validate()
+	err = cfg.Validate()
 	require.NoError(t, err, "有效配置应该验证通过")
 }
 
@@ -119,101 +125,75 @@ func TestValidate_MissingConnectTo(t *testing.T) {
 	require.Contains(t, err.Error(), "ConnectTo URL is required")
 }
 
-func TestLoadACLRules_ValidFile(t *testing.T) {
-	// 创建临时YAML文件
-	tmpDir := t.TempDir()
-	aclFile := filepath.Join(tmpDir, "acl.yaml")
-
-	yamlContent := `
-rule1:
-  Tag: "test-rule-1"
-  Rules:
-    - IsPermit: 1
-      Proto: 6
-      SrcPort: 0
-      DstPort: 80
-rule2:
-  Tag: "test-rule-2"
-  Rules:
-    - IsPermit: 1
-      Proto: 17
-      SrcPort: 0
-      DstPort: 53
-`
-	err := os.WriteFile(aclFile, []byte(yamlContent), 0600)
-	require.NoError(t, err)
-
-	// 加载ACL规则
-	ctx := context.Background()
+func TestValidate_MissingNATConfig(t *testing.T) {
 	cfg := &config.Config{
-		ACLConfigPath: aclFile,
+		Name:        "test-server",
+		ServiceName: "test-service",
+		ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
 	}
-	cfg.LoadACLRules(ctx)
 
-	// 验证加载了规则（ACLRule的具体结构由外部库定义，这里只验证数量）
-	require.Len(t, cfg.ACLConfig, 2, "应该加载2条ACL规则")
+	err := cfg.Validate()
+	require.Error(t, err, "未加载NAT配置应该返回错误")
+	require.Contains(t, err.Error(), "NAT configuration is required")
 }
 
-func TestLoadACLRules_FileNotFound(t *testing.T) {
-	// 使用不存在的文件路径
-	ctx := context.Background()
-	cfg := &config.Config{
-		ACLConfigPath: "/nonexistent/path/acl.yaml",
-	}
+// validNATConfig 最小的有效NAT配置
+const validNATConfig = `
+name: nat-nse
+natIP: "203.0.113.10"
+snatRules:
+  - srcNet: "10.0.0.0/8"
+`
 
-	// 调用LoadACLRules不应该panic，只是记录错误
-	require.NotPanics(t, func() {
-		cfg.LoadACLRules(ctx)
-	})
+func TestLoadNATConfig_ValidFile(t *testing.T) {
+	natFile := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(natFile, []byte(validNATConfig), 0600)
+	require.NoError(t, err)
 
-	// 没有加载任何规则
-	require.Empty(t, cfg.ACLConfig)
+	cfg := &config.Config{NATConfigPath: natFile}
+	require.NoError(t, cfg.LoadNATConfig())
+
+	// 验证默认值已应用
+	require.Equal(t, "203.0.113.10", cfg.NATConfig.NatIP)
+	require.Equal(t, config.SNATActionSNAT, cfg.NATConfig.DefaultAction, "未设置defaultAction时应保持全部连接做SNAT")
+	require.Equal(t, config.SNATActionSNAT, cfg.NATConfig.SnatRules[0].Action)
+	require.Equal(t, config.DefaultPortRange(), cfg.NATConfig.PortRange)
+	require.Equal(t, config.DefaultNATTimeouts(), cfg.NATConfig.Timeouts)
+}
+
+func TestLoadNATConfig_FileNotFound(t *testing.T) {
+	cfg := &config.Config{NATConfigPath: "/nonexistent/path/config.yaml"}
+
+	err := cfg.LoadNATConfig()
+	require.Error(t, err)
+	require.Nil(t, cfg.NATConfig)
 }
 
-func TestLoadACLRules_InvalidYAML(t *testing.T) {
-	// 创建无效的YAML文件
-	tmpDir := t.TempDir()
-	aclFile := filepath.Join(tmpDir, "invalid.yaml")
+func TestLoadNATConfig_InvalidYAML(t *testing.T) {
+	natFile := filepath.Join(t.TempDir(), "invalid.yaml")
 
 	invalidContent := `
 this is not
   valid: yaml: content:
     - broken
 `
-	err := os.WriteFile(aclFile, []byte(invalidContent), 0600)
+	err := os.WriteFile(natFile, []byte(invalidContent), 0600)
 	require.NoError(t, err)
 
-	ctx := context.Background()
-	cfg := &config.Config{
-		ACLConfigPath: aclFile,
-	}
-
-	// 调用LoadACLRules不应该panic
-	require.NotPanics(t, func() {
-		cfg.LoadACLRules(ctx)
-	})
-
-	// 没有成功加载规则
-	require.Empty(t, cfg.ACLConfig)
+	cfg := &config.Config{NATConfigPath: natFile}
+	require.Error(t, cfg.LoadNATConfig())
+	require.Nil(t, cfg.NATConfig)
 }
 
-func TestLoadACLRules_EmptyFile(t *testing.T) {
-	// 创建空文件
-	tmpDir := t.TempDir()
-	aclFile := filepath.Join(tmpDir, "empty.yaml")
-
-	err := os.WriteFile(aclFile, []byte(""), 0600)
+func TestLoadNATConfig_EmptyFile(t *testing.T) {
+	natFile := filepath.Join(t.TempDir(), "empty.yaml")
+	err := os.WriteFile(natFile, []byte(""), 0600)
 	require.NoError(t, err)
 
-	ctx := context.Background()
-	cfg := &config.Config{
-		ACLConfigPath: aclFile,
-	}
-
-	cfg.LoadACLRules(ctx)
-
-	// 空文件不应该加载任何规则
-	require.Empty(t, cfg.ACLConfig)
+	// 空文件可以解析,但缺少必填字段,验证失败
+	cfg := &config.Config{NATConfigPath: natFile}
+	require.Error(t, cfg.LoadNATConfig())
+	require.Nil(t, cfg.NATConfig)
 }
 
 // 辅助函数：清理环境变量
@@ -226,8 +206,7 @@ func clearEnv(t *testing.T) {
 		"NSM_REGISTRY_CLIENT_POLICIES",
 		"NSM_SERVICE_NAME",
 		"NSM_LABELS",
-		"NSM_ACL_CONFIG_PATH",
-		"NSM_ACL_CONFIG",
+		"NSM_NAT_CONFIG_PATH",
 		"NSM_LOG_LEVEL",
 		"NSM_OPEN_TELEMETRY_ENDPOINT",
 		"NSM_METRICS_EXPORT_INTERVAL",
@@ -0,0 +1,191 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 验证错误码，供CI等工具按类型处理验证结果
+const (
+	// CodeRequired 必填字段缺失
+	CodeRequired = "required"
+
+	// CodeInvalidIP IP地址格式错误
+	CodeInvalidIP = "invalid_ip"
+
+	// CodeNotIPv4 地址不是IPv4
+	CodeNotIPv4 = "not_ipv4"
+
+	// CodeInvalidCIDR CIDR格式错误
+	CodeInvalidCIDR = "invalid_cidr"
+
+	// CodeInvalidAddressRange 地址池条目格式错误
+	CodeInvalidAddressRange = "invalid_address_range"
+
+	// CodeOutOfRange 数值超出允许范围
+	CodeOutOfRange = "out_of_range"
+
+	// CodeInvalidValue 枚举值不合法
+	CodeInvalidValue = "invalid_value"
+
+	// CodeInvalidPattern 匹配模式语法错误
+	CodeInvalidPattern = "invalid_pattern"
+
+	// CodeDuplicate 值重复
+	CodeDuplicate = "duplicate"
+
+	// CodeOverlap 地址范围重叠
+	CodeOverlap = "overlap"
+
+	// CodeUnknownReference 引用了不存在的对象
+	CodeUnknownReference = "unknown_reference"
+
+	// CodeUnknownField 配置文件中出现了未定义的字段（通常是拼写错误）
+	CodeUnknownField = "unknown_field"
+
+	// CodePrefixLengthMismatch 成对的前缀长度不一致
+	CodePrefixLengthMismatch = "prefix_length_mismatch"
+
+	// CodeRangeSizeMismatch 成对的端口范围大小不一致
+	CodeRangeSizeMismatch = "range_size_mismatch"
+
+	// CodeNotInPool DNAT外部地址既不是natIP也不在任何地址池中
+	CodeNotInPool = "not_in_pool"
+
+	// CodePortConflict DNAT外部端口落在SNAT动态端口范围内
+	CodePortConflict = "port_conflict"
+
+	// CodeNotCovered 地址未被任何SNAT规则覆盖
+	CodeNotCovered = "not_covered"
+
+	// CodeShadowed SNAT规则被更宽的同动作规则覆盖，不起作用
+	CodeShadowed = "shadowed"
+
+	// CodeSelfTranslated SNAT地址位于SNAT源网段内
+	CodeSelfTranslated = "self_translated"
+
+	// CodeNoEffect 选项已启用，但缺少使其生效的配置
+	CodeNoEffect = "no_effect"
+
+	// CodeConflict 配置项与其他规则矛盾，不能同时生效
+	CodeConflict = "conflict"
+)
+
+// 验证结果的严重程度
+const (
+	// SeverityError 配置无效，不能生效
+	SeverityError = "error"
+
+	// SeverityWarning 配置可以生效，但字段之间的组合可能不符合预期
+	SeverityWarning = "warning"
+)
+
+// FieldError 单个字段的验证错误
+type FieldError struct {
+	// Field 字段路径，如"dnatRules[3].internalPort"，整体性错误时为空
+	Field string `json:"field,omitempty"`
+
+	// Code 机器可读的错误码（Code*常量）
+	Code string `json:"code"`
+
+	// Message 错误描述
+	Message string `json:"message"`
+
+	// Severity 严重程度（Severity*常量）
+	Severity string `json:"severity"`
+
+	// Line 错误在配置文件中的行号（从1开始，未知时为0）
+	Line int `json:"line,omitempty"`
+
+	// Column 错误在配置文件中的列号（从1开始，未知时为0）
+	Column int `json:"column,omitempty"`
+}
+
+// Error 实现error接口，格式为"<field>: <message>"，已知位置时附加"(line L, column C)"
+func (e *FieldError) Error() string {
+	msg := e.Message
+	if e.Field != "" {
+		msg = e.Field + ": " + msg
+	}
+	if e.Line > 0 {
+		msg += fmt.Sprintf(" (line %d, column %d)", e.Line, e.Column)
+	}
+	return msg
+}
+
+// ValidationErrors 一次验证发现的全部错误
+//
+// 可通过errors.As取出后逐条输出：
+//
+//	var verrs config.ValidationErrors
+//	if errors.As(err, &verrs) {
+//	    for _, fe := range verrs {
+//	        fmt.Println(fe.Field, fe.Code, fe.Message)
+//	    }
+//	}
+type ValidationErrors []*FieldError
+
+// Error 实现error接口，将全部错误以"; "连接
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return fmt.Sprintf("%d validation errors: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Unwrap 返回各字段错误，支持errors.Is/errors.As逐条匹配
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fe := range e {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// validator 收集验证错误和警告
+type validator struct {
+	errs     ValidationErrors
+	warnings ValidationErrors
+}
+
+// addf 记录一条字段错误
+func (v *validator) addf(field, code, format string, args ...interface{}) {
+	v.errs = append(v.errs, &FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...), Severity: SeverityError})
+}
+
+// warnf 记录一条警告，警告不会使验证失败
+func (v *validator) warnf(field, code, format string, args ...interface{}) {
+	v.warnings = append(v.warnings, &FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...), Severity: SeverityWarning})
+}
+
+// err 没有错误时返回nil，否则返回ValidationErrors
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import "sync/atomic"
+
+// NATConfigHolder 可热更新的NAT配置
+//
+// 链节点每次处理请求时通过Load读取当前配置,
+// 配置重新加载成功后通过Store原子替换。
+type NATConfigHolder struct {
+	cfg atomic.Pointer[NATConfig]
+}
+
+// NewNATConfigHolder 创建持有初始配置的NATConfigHolder
+//
+// 示例:
+//
+//	holder := config.NewNATConfigHolder(cfg.NATConfig)
+//	natIP := holder.Load().NatIP
+func NewNATConfigHolder(cfg *NATConfig) *NATConfigHolder {
+	h := &NATConfigHolder{}
+	h.cfg.Store(cfg)
+	return h
+}
+
+// Load 返回当前生效的NAT配置,调用方不得修改返回值
+func (h *NATConfigHolder) Load() *NATConfig {
+	return h.cfg.Load()
+}
+
+// Store 替换当前生效的NAT配置
+func (h *NATConfigHolder) Store(cfg *NATConfig) {
+	h.cfg.Store(cfg)
+}
@@ -20,6 +20,12 @@
 
 package config
 
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
 // NATConfig NAT配置顶层实体
 //
 // 包含所有NAT相关配置参数，对应data-model.md中的NATConfig实体。
@@ -31,20 +37,59 @@ type NATConfig struct {
 	// Labels Kubernetes标签，用于服务发现和路由
 	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
 
-	// NatIP SNAT外部IP地址（IPv4格式）
-	NatIP string `yaml:"natIP" json:"natIP"`
+	// NatIP SNAT外部IP地址（IPv4格式，与NatPool至少配置一个）
+	NatIP string `yaml:"natIP,omitempty" json:"natIP,omitempty"`
+
+	// NatPool SNAT地址池（可选），每个条目为单个地址、"a.b.c.d-e.f.g.h"范围或CIDR
+	NatPool []string `yaml:"natPool,omitempty" json:"natPool,omitempty"`
+
+	// Pools 命名地址池（可选），按PoolSelectors为不同租户分配不同的出口地址
+	Pools []NATPool `yaml:"pools,omitempty" json:"pools,omitempty"`
+
+	// PoolSelectors 地址池选择器（可选），按顺序匹配，未匹配时使用natIP/natPool
+	PoolSelectors []PoolSelector `yaml:"poolSelectors,omitempty" json:"poolSelectors,omitempty"`
+
+	// TwiceNATPool twice-NAT地址池（可选），格式同NatPool，
+	// 启用twiceNAT或selfTwiceNAT的DNAT规则从中选取转换后的源地址
+	TwiceNATPool []string `yaml:"twiceNATPool,omitempty" json:"twiceNATPool,omitempty"`
 
 	// PortRange SNAT端口池范围（可选，默认1024-65535）
+	//
+	// nat44-ed没有端口分配范围设置，动态SNAT始终使用1024-65535，
+	// 配置其他范围不会生效，CheckNATConfig会给出警告。
 	PortRange *PortRange `yaml:"portRange,omitempty" json:"portRange,omitempty"`
 
 	// SnatRules SNAT规则列表（至少1条）
 	SnatRules []SNATRule `yaml:"snatRules" json:"snatRules"`
 
+	// DefaultAction 未匹配任何SNAT规则的源地址的处理策略（"snat"、"forward"或"drop"，默认"snat"）
+	//
+	// 默认值与引入规则动作之前的行为一致：全部连接都做SNAT，snatRules不限制转换范围。
+	// 只需转换snatRules覆盖的源地址时，应显式设置为"forward"或"drop"。
+	DefaultAction string `yaml:"defaultAction,omitempty" json:"defaultAction,omitempty"`
+
 	// DnatRules DNAT规则列表（可选，P2优先级）
 	DnatRules []DNATRule `yaml:"dnatRules,omitempty" json:"dnatRules,omitempty"`
 
+	// LBRules 负载均衡DNAT规则（可选），一个外部端点按权重分发到多个内部后端
+	LBRules []LBRule `yaml:"lbRules,omitempty" json:"lbRules,omitempty"`
+
+	// Exemptions NAT豁免列表（可选），匹配的inside地址或地址+端口不做转换，
+	// 下发为nat44身份映射
+	Exemptions []Exemption `yaml:"exemptions,omitempty" json:"exemptions,omitempty"`
+
+	// Hairpinning 回环模式（可选，默认关闭），允许做SNAT的inside客户端
+	// 通过DNAT规则的externalIP:externalPort访问同一NSE后面的内部服务
+	Hairpinning bool `yaml:"hairpinning,omitempty" json:"hairpinning,omitempty"`
+
 	// Timeouts NAT会话超时参数（可选，P4优先级）
 	Timeouts *NATTimeouts `yaml:"timeouts,omitempty" json:"timeouts,omitempty"`
+
+	// NAT64 NAT64配置（可选），为IPv6客户端提供到IPv4网络的访问
+	NAT64 *NAT64Config `yaml:"nat64,omitempty" json:"nat64,omitempty"`
+
+	// NPTv6 IPv6前缀转换规则（可选），对IPv6流量做无状态前缀替换
+	NPTv6 []NPTv6Rule `yaml:"nptv6,omitempty" json:"nptv6,omitempty"`
 }
 
 // PortRange 端口范围配置
@@ -59,13 +104,85 @@ type PortRange struct {
 	End uint16 `yaml:"end" json:"end"`
 }
 
+// NATPool 命名SNAT地址池
+//
+// 选中该地址池的连接引用其地址，首个使用者将地址下发到VPP，最后一个使用者释放后删除。
+// 地址均位于默认VRF，nat44-ed在全部已下发的地址中分配出口地址。
+type NATPool struct {
+	// Name 地址池名称（唯一）
+	Name string `yaml:"name" json:"name"`
+
+	// Addresses 地址条目，格式同NatPool
+	Addresses []string `yaml:"addresses" json:"addresses"`
+}
+
+// PoolSelector 地址池选择器
+//
+// 所有已设置的条件都满足时选中Pool。
+type PoolSelector struct {
+	// Pool 选中的地址池名称
+	Pool string `yaml:"pool" json:"pool"`
+
+	// MatchLabels 需匹配的NetworkServiceRequest标签（如podName、namespace）
+	MatchLabels map[string]string `yaml:"matchLabels,omitempty" json:"matchLabels,omitempty"`
+
+	// SpiffeID 客户端SPIFFE ID匹配模式（path.Match语法，如"spiffe://example.org/ns/tenant-a/*"）
+	SpiffeID string `yaml:"spiffeID,omitempty" json:"spiffeID,omitempty"`
+}
+
+// WellKnownNAT64Prefix RFC 6052定义的NAT64知名前缀
+const WellKnownNAT64Prefix = "64:ff9b::/96"
+
+// NAT64Config NAT64配置
+//
+// IPv6客户端访问Prefix内的地址（前缀+IPv4目的地址）时，
+// VPP nat64插件将报文转换为IPv4，源地址从Pool中分配。
+// 只有匹配action为snat的IPv6 SnatRules的客户端才会启用NAT64。
+type NAT64Config struct {
+	// Prefix NAT64前缀（IPv6 CIDR，长度为32、40、48、56、64或96，默认64:ff9b::/96）
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// Pool IPv4地址池，格式同NatPool
+	Pool []string `yaml:"pool" json:"pool"`
+}
+
+// NPTv6Rule NPTv6前缀映射（RFC 6296）
+//
+// 出方向将源地址的InsidePrefix替换为OutsidePrefix，入方向反之，
+// 接口标识部分保持不变，因此两个前缀的长度必须相同。
+type NPTv6Rule struct {
+	// InsidePrefix 内部前缀（如"fd00:1::/48"）
+	InsidePrefix string `yaml:"insidePrefix" json:"insidePrefix"`
+
+	// OutsidePrefix 外部前缀（如"2001:db8:1::/48"）
+	OutsidePrefix string `yaml:"outsidePrefix" json:"outsidePrefix"`
+}
+
+// SNAT规则动作
+const (
+	// SNATActionSNAT 对匹配的源地址执行SNAT转换
+	SNATActionSNAT = "snat"
+
+	// SNATActionForward 匹配的源地址不做转换，直接转发
+	SNATActionForward = "forward"
+
+	// SNATActionDrop 拒绝匹配的源地址所在的NSM连接（在建立连接时拒绝，不在数据面逐包丢弃）
+	SNATActionDrop = "drop"
+)
+
 // SNATRule SNAT规则配置
 //
 // 定义允许进行SNAT转换的源网段。
 // 对应data-model.md中的SNATRule实体。
+//
+// 规则按连接生效：inside接口上的NAT特性作用于接口的全部流量，
+// 因此一个连接的全部源地址必须命中同一动作，否则连接会被拒绝。
 type SNATRule struct {
 	// SrcNet 源网段（CIDR格式，如"192.168.1.0/24"或"0.0.0.0/0"）
 	SrcNet string `yaml:"srcNet" json:"srcNet"`
+
+	// Action 匹配此网段时的动作（"snat"、"forward"或"drop"，默认"snat"）
+	Action string `yaml:"action,omitempty" json:"action,omitempty"`
 }
 
 // DNATRule DNAT规则配置
@@ -73,21 +190,204 @@ type SNATRule struct {
 // 定义外部IP/端口到内部IP/端口的静态映射（端口转发）。
 // 对应data-model.md中的DNATRule实体。
 // P2优先级功能。
+//
+// 规则有三种形式：
+//   - 单端口：externalPort映射到internalPort
+//   - 端口范围：设置externalPortEnd和internalPortEnd，外部范围逐个端口映射到大小相同的内部范围
+//   - 地址一对一：不设置端口和协议，外部地址的全部协议（包括ICMP）映射到内部地址
 type DNATRule struct {
 	// ExternalIP 外部IP地址（公网IP）
 	ExternalIP string `yaml:"externalIP" json:"externalIP"`
 
-	// ExternalPort 外部端口
-	ExternalPort uint16 `yaml:"externalPort" json:"externalPort"`
+	// ExternalPort 外部端口（端口范围的起始端口，地址一对一映射时省略）
+	ExternalPort uint16 `yaml:"externalPort,omitempty" json:"externalPort,omitempty"`
+
+	// ExternalPortEnd 外部端口范围的结束端口（可选，包含在范围内）
+	ExternalPortEnd uint16 `yaml:"externalPortEnd,omitempty" json:"externalPortEnd,omitempty"`
 
 	// InternalIP 内部服务器IP地址（私有IP）
 	InternalIP string `yaml:"internalIP" json:"internalIP"`
 
-	// InternalPort 内部服务器端口
-	InternalPort uint16 `yaml:"internalPort" json:"internalPort"`
+	// InternalPort 内部服务器端口（端口范围的起始端口，地址一对一映射时省略）
+	InternalPort uint16 `yaml:"internalPort,omitempty" json:"internalPort,omitempty"`
+
+	// InternalPortEnd 内部端口范围的结束端口（可选，范围大小须与外部范围相同）
+	InternalPortEnd uint16 `yaml:"internalPortEnd,omitempty" json:"internalPortEnd,omitempty"`
+
+	// Protocol 协议（"tcp"、"udp"或同时映射两者的"both"，地址一对一映射时省略）
+	Protocol string `yaml:"protocol,omitempty" json:"protocol,omitempty"`
+
+	// TwiceNAT 同时将源地址转换为twiceNATPool中的地址，内部服务只看到NSE的地址
+	TwiceNAT bool `yaml:"twiceNAT,omitempty" json:"twiceNAT,omitempty"`
+
+	// SelfTwiceNAT 只在内部服务经外部地址访问自身时转换源地址（与TwiceNAT互斥）
+	SelfTwiceNAT bool `yaml:"selfTwiceNAT,omitempty" json:"selfTwiceNAT,omitempty"`
+}
+
+// DNATProtocolBoth 同时安装TCP和UDP映射的DNAT协议
+const DNATProtocolBoth = "both"
+
+// IsAddressOnly 规则未设置任何端口时为地址一对一映射
+func (r DNATRule) IsAddressOnly() bool {
+	return r.ExternalPort == 0 && r.ExternalPortEnd == 0 && r.InternalPort == 0 && r.InternalPortEnd == 0
+}
+
+// ExternalPorts 返回外部端口范围，未设置结束端口时为单个端口
+func (r DNATRule) ExternalPorts() (start, end uint16) {
+	return portSpan(r.ExternalPort, r.ExternalPortEnd)
+}
+
+// InternalPorts 返回内部端口范围，未设置结束端口时为单个端口
+func (r DNATRule) InternalPorts() (start, end uint16) {
+	return portSpan(r.InternalPort, r.InternalPortEnd)
+}
+
+// Protocols 返回规则映射的协议（小写），"both"展开为tcp和udp，地址一对一映射返回nil
+func (r DNATRule) Protocols() []string {
+	if r.IsAddressOnly() {
+		return nil
+	}
+	protocol := strings.ToLower(r.Protocol)
+	if protocol == DNATProtocolBoth {
+		return []string{"tcp", "udp"}
+	}
+	return []string{protocol}
+}
+
+// Expand 将规则展开为VPP静态映射，每个协议的每个端口一条
+//
+// 展开后的映射只设置ExternalPort和InternalPort；地址一对一映射原样返回。
+// 范围无效（结束端口小于起始端口或大小不同）时按较短的范围展开，由校验负责报告。
+func (r DNATRule) Expand() []DNATRule {
+	if r.IsAddressOnly() {
+		return []DNATRule{{
+			ExternalIP:   r.ExternalIP,
+			InternalIP:   r.InternalIP,
+			TwiceNAT:     r.TwiceNAT,
+			SelfTwiceNAT: r.SelfTwiceNAT,
+		}}
+	}
+
+	extStart, extEnd := r.ExternalPorts()
+	intStart, intEnd := r.InternalPorts()
+	count := int(extEnd) - int(extStart) + 1
+	if n := int(intEnd) - int(intStart) + 1; n < count {
+		count = n
+	}
+
+	var mappings []DNATRule
+	for _, protocol := range r.Protocols() {
+		for i := 0; i < count; i++ {
+			mappings = append(mappings, DNATRule{
+				ExternalIP:   r.ExternalIP,
+				ExternalPort: extStart + uint16(i),
+				InternalIP:   r.InternalIP,
+				InternalPort: intStart + uint16(i),
+				Protocol:     protocol,
+				TwiceNAT:     r.TwiceNAT,
+				SelfTwiceNAT: r.SelfTwiceNAT,
+			})
+		}
+	}
+	return mappings
+}
+
+// String 返回规则的可读形式，如"tcp 203.0.113.10:80->10.0.1.100:8080"、
+// "both 203.0.113.10:5000-5009->10.0.1.100:6000-6009"或"203.0.113.11->10.0.1.101"
+func (r DNATRule) String() string {
+	if r.IsAddressOnly() {
+		return fmt.Sprintf("%s->%s", r.ExternalIP, r.InternalIP)
+	}
+	return fmt.Sprintf("%s %s->%s", strings.ToLower(r.Protocol),
+		formatPortSpan(r.ExternalIP, r.ExternalPort, r.ExternalPortEnd),
+		formatPortSpan(r.InternalIP, r.InternalPort, r.InternalPortEnd))
+}
+
+// portSpan 返回起始端口和结束端口，end为0时范围只包含start
+func portSpan(start, end uint16) (uint16, uint16) {
+	if end == 0 {
+		return start, start
+	}
+	return start, end
+}
+
+// formatPortSpan 返回"ip:port"或"ip:start-end"
+func formatPortSpan(ip string, start, end uint16) string {
+	if end == 0 || end == start {
+		return fmt.Sprintf("%s:%d", ip, start)
+	}
+	return fmt.Sprintf("%s:%d-%d", ip, start, end)
+}
+
+// DefaultLBWeight 未设置权重的负载均衡后端使用的权重
+const DefaultLBWeight = 1
+
+// LBRule 负载均衡DNAT规则
+//
+// 将 externalIP:externalPort 上的新会话按权重分发到多个后端，对应VPP的负载均衡静态映射。
+// 至少需要2个后端，单个后端请使用DNATRule。
+type LBRule struct {
+	// ExternalIP 外部IP地址（公网IP）
+	ExternalIP string `yaml:"externalIP" json:"externalIP"`
+
+	// ExternalPort 外部端口
+	ExternalPort uint16 `yaml:"externalPort" json:"externalPort"`
 
 	// Protocol 协议（"tcp"或"udp"）
 	Protocol string `yaml:"protocol" json:"protocol"`
+
+	// Backends 内部后端列表（至少2个）
+	Backends []LBBackend `yaml:"backends" json:"backends"`
+}
+
+// LBBackend 负载均衡DNAT规则的内部后端
+type LBBackend struct {
+	// IP 后端IP地址（私有IP）
+	IP string `yaml:"ip" json:"ip"`
+
+	// Port 后端端口
+	Port uint16 `yaml:"port" json:"port"`
+
+	// Weight 相对权重（1-255，默认1），新会话按权重占比选择后端
+	Weight uint8 `yaml:"weight,omitempty" json:"weight,omitempty"`
+}
+
+// Endpoint 返回规则外部端点的可读形式，如"tcp 203.0.113.10:80"
+func (r *LBRule) Endpoint() string {
+	return fmt.Sprintf("%s %s:%d", strings.ToLower(r.Protocol), r.ExternalIP, r.ExternalPort)
+}
+
+// FindBackend 返回地址和端口相同的后端的下标，没有时返回-1
+func (r *LBRule) FindBackend(ip string, port uint16) int {
+	for i, backend := range r.Backends {
+		if backend.IP == ip && backend.Port == port {
+			return i
+		}
+	}
+	return -1
+}
+
+// Exemption NAT豁免
+//
+// 未设置Port时豁免整个地址（所有协议）；设置Port时只豁免该协议端口，此时Protocol必填。
+// 例如监控探针、DNS转发器等需要以真实地址访问外部网络的inside主机。
+type Exemption struct {
+	// IP inside地址（IPv4）
+	IP string `yaml:"ip" json:"ip"`
+
+	// Port 端口（可选，0表示整个地址）
+	Port uint16 `yaml:"port,omitempty" json:"port,omitempty"`
+
+	// Protocol 协议（"tcp"或"udp"，设置Port时必填）
+	Protocol string `yaml:"protocol,omitempty" json:"protocol,omitempty"`
+}
+
+// String 返回豁免的可读形式，如"10.0.1.53"或"udp 10.0.1.53:53"
+func (e Exemption) String() string {
+	if e.Port == 0 {
+		return e.IP
+	}
+	return fmt.Sprintf("%s %s:%d", strings.ToLower(e.Protocol), e.IP, e.Port)
 }
 
 // NATTimeouts NAT会话超时参数
@@ -109,7 +409,7 @@ type NATTimeouts struct {
 	Icmp uint32 `yaml:"icmp,omitempty" json:"icmp,omitempty"`
 }
 
-// DefaultPortRange 返回默认端口范围配置
+// DefaultPortRange 返回默认端口范围配置，也是nat44-ed实际分配动态SNAT端口的范围
 func DefaultPortRange() *PortRange {
 	return &PortRange{
 		Start: 1024,
@@ -129,6 +429,102 @@ func DefaultNATTimeouts() *NATTimeouts {
 	}
 }
 
+// MatchSNATAction 根据源地址查找应执行的SNAT动作
+//
+// 按最长前缀匹配SnatRules中的SrcNet，返回命中规则的动作；
+// 未命中任何规则时返回DefaultAction，此时rule为nil。
+//
+// 示例：
+//
+//	action, rule := natCfg.MatchSNATAction(net.ParseIP("10.0.1.5"))
+//	if action == config.SNATActionSNAT {
+//	    // 配置NAT inside接口
+//	}
+func (c *NATConfig) MatchSNATAction(ip net.IP) (action string, rule *SNATRule) {
+	bestLen := -1
+	for i := range c.SnatRules {
+		_, ipNet, err := net.ParseCIDR(c.SnatRules[i].SrcNet)
+		if err != nil || !ipNet.Contains(ip) {
+			continue
+		}
+		if ones, _ := ipNet.Mask.Size(); ones > bestLen {
+			bestLen = ones
+			rule = &c.SnatRules[i]
+		}
+	}
+
+	if rule == nil {
+		return c.defaultSNATAction(), nil
+	}
+	return rule.Action, rule
+}
+
+// defaultSNATAction 返回未命中规则时的动作，未设置DefaultAction时为snat
+func (c *NATConfig) defaultSNATAction() string {
+	if c.DefaultAction == "" {
+		return SNATActionSNAT
+	}
+	return c.DefaultAction
+}
+
+// MatchConnectionAction 根据连接的全部源地址确定连接的SNAT动作
+//
+// 策略按连接生效：NAT inside特性配置在连接的inside接口上，作用于接口的全部流量，
+// 无法只转换其中一部分源地址。因此每个源地址都单独匹配，全部命中同一动作时返回该动作；
+// 动作不一致时返回错误，由调用方拒绝连接。
+//
+// 只匹配SnatRules中有规则的地址族：双栈客户端的IPv4地址命中IPv4规则时，
+// 没有对应IPv6规则的IPv6地址不参与决策，不会因落入DefaultAction而被拒绝。
+// 没有可参与匹配的源地址时返回DefaultAction。
+//
+// 示例：
+//
+//	action, err := natCfg.MatchConnectionAction(srcIPs)
+//	if err != nil {
+//	    // 源地址混合了不同动作，拒绝连接
+//	}
+func (c *NATConfig) MatchConnectionAction(ips []net.IP) (string, error) {
+	hasV4, hasV6 := c.snatRuleFamilies()
+
+	var first net.IP
+	var action string
+	for _, ip := range ips {
+		if isV4 := ip.To4() != nil; (isV4 && !hasV4) || (!isV4 && !hasV6) {
+			continue
+		}
+		other, _ := c.MatchSNATAction(ip)
+		if first == nil {
+			first, action = ip, other
+			continue
+		}
+		if other != action {
+			return "", fmt.Errorf("source addresses of one connection match different SNAT actions: %s is '%s', %s is '%s'",
+				first, action, ip, other)
+		}
+	}
+
+	if first == nil {
+		return c.defaultSNATAction(), nil
+	}
+	return action, nil
+}
+
+// snatRuleFamilies 返回SnatRules中是否有IPv4规则和IPv6规则
+func (c *NATConfig) snatRuleFamilies() (hasV4, hasV6 bool) {
+	for _, rule := range c.SnatRules {
+		_, ipNet, err := net.ParseCIDR(rule.SrcNet)
+		if err != nil {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			hasV4 = true
+		} else {
+			hasV6 = true
+		}
+	}
+	return hasV4, hasV6
+}
+
 // AvailablePortsCount 计算可用端口数量
 func (pr *PortRange) AvailablePortsCount() int {
 	if pr == nil {
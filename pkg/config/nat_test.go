@@ -0,0 +1,143 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+func snatConfig() *config.NATConfig {
+	return &config.NATConfig{
+		SnatRules: []config.SNATRule{
+			{SrcNet: "10.0.0.0/8", Action: config.SNATActionSNAT},
+			{SrcNet: "10.1.0.0/16", Action: config.SNATActionForward},
+			{SrcNet: "10.1.2.0/24", Action: config.SNATActionDrop},
+			{SrcNet: "fd00::/8", Action: config.SNATActionSNAT},
+		},
+		DefaultAction: config.SNATActionForward,
+	}
+}
+
+func TestMatchSNATAction(t *testing.T) {
+	cfg := snatConfig()
+
+	for _, tc := range []struct {
+		ip     string
+		action string
+		srcNet string
+	}{
+		{ip: "10.9.9.9", action: config.SNATActionSNAT, srcNet: "10.0.0.0/8"},
+		{ip: "10.1.9.9", action: config.SNATActionForward, srcNet: "10.1.0.0/16"},
+		{ip: "10.1.2.3", action: config.SNATActionDrop, srcNet: "10.1.2.0/24"},
+		{ip: "fd00::5", action: config.SNATActionSNAT, srcNet: "fd00::/8"},
+		{ip: "192.168.1.1", action: config.SNATActionForward},
+	} {
+		t.Run(tc.ip, func(t *testing.T) {
+			action, rule := cfg.MatchSNATAction(net.ParseIP(tc.ip))
+			require.Equal(t, tc.action, action)
+			if tc.srcNet == "" {
+				require.Nil(t, rule, "未命中规则时应使用defaultAction")
+				return
+			}
+			require.NotNil(t, rule)
+			require.Equal(t, tc.srcNet, rule.SrcNet, "应按最长前缀匹配")
+		})
+	}
+}
+
+func TestMatchSNATAction_DefaultsToSNAT(t *testing.T) {
+	cfg := &config.NATConfig{SnatRules: []config.SNATRule{{SrcNet: "10.0.0.0/8", Action: config.SNATActionSNAT}}}
+
+	action, rule := cfg.MatchSNATAction(net.ParseIP("192.168.1.1"))
+	require.Equal(t, config.SNATActionSNAT, action, "未设置defaultAction时未命中规则的地址也应做SNAT")
+	require.Nil(t, rule)
+}
+
+func TestMatchConnectionAction(t *testing.T) {
+	cfg := snatConfig()
+
+	for _, tc := range []struct {
+		name    string
+		ips     []string
+		action  string
+		wantErr bool
+	}{
+		{name: "no source", action: config.SNATActionForward},
+		{name: "single", ips: []string{"10.9.9.9"}, action: config.SNATActionSNAT},
+		{name: "dual stack same action", ips: []string{"10.9.9.9", "fd00::5"}, action: config.SNATActionSNAT},
+		{name: "mixed snat and forward", ips: []string{"10.9.9.9", "10.1.9.9"}, wantErr: true},
+		{name: "mixed snat and default", ips: []string{"10.9.9.9", "192.168.1.1"}, wantErr: true},
+		{name: "all drop", ips: []string{"10.1.2.3", "10.1.2.4"}, action: config.SNATActionDrop},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var ips []net.IP
+			for _, ip := range tc.ips {
+				ips = append(ips, net.ParseIP(ip))
+			}
+
+			action, err := cfg.MatchConnectionAction(ips)
+			if tc.wantErr {
+				require.Error(t, err, "源地址动作不一致时应拒绝连接")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.action, action)
+		})
+	}
+}
+
+func TestMatchConnectionAction_DualStack(t *testing.T) {
+	cfg := &config.NATConfig{
+		SnatRules:     []config.SNATRule{{SrcNet: "10.0.0.0/8", Action: config.SNATActionSNAT}},
+		DefaultAction: config.SNATActionForward,
+	}
+
+	for _, tc := range []struct {
+		name    string
+		ips     []string
+		action  string
+		wantErr bool
+	}{
+		{name: "ipv6 without rules is ignored", ips: []string{"10.9.9.9", "fd00::5"}, action: config.SNATActionSNAT},
+		{name: "ipv6 only uses default", ips: []string{"fd00::5"}, action: config.SNATActionForward},
+		{name: "ipv4 default still conflicts", ips: []string{"10.9.9.9", "192.168.1.1", "fd00::5"}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var ips []net.IP
+			for _, ip := range tc.ips {
+				ips = append(ips, net.ParseIP(ip))
+			}
+
+			action, err := cfg.MatchConnectionAction(ips)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err, "双栈客户端不应因没有规则的地址族被拒绝")
+			require.Equal(t, tc.action, action)
+		})
+	}
+}
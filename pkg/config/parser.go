@@ -21,17 +21,21 @@
 package config
 
 import (
+	"bytes"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 
 	"github.com/pkg/errors"
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 )
 
 // LoadNATConfigFromFile 从YAML文件加载NAT配置
 //
-// 读取指定路径的YAML配置文件，解析为NATConfig结构体。
-// 支持相对路径和绝对路径。
+// 读取指定路径的YAML配置文件，严格解析为NATConfig结构体。
+// 支持相对路径和绝对路径。未知字段（如拼写错误的"natIp"）会被拒绝，
+// 返回的错误中包含所有未知字段的行号和列号（见decodeStrict）。
 //
 // 参数：
 //   - configPath: YAML配置文件路径
@@ -53,9 +57,9 @@ func LoadNATConfigFromFile(configPath string) (*NATConfig, error) {
 		return nil, errors.Wrapf(err, "failed to read NAT config file: %s", configPath)
 	}
 
-	// 解析YAML
+	// 严格解析YAML
 	var natCfg NATConfig
-	if err := yaml.Unmarshal(raw, &natCfg); err != nil {
+	if err := decodeStrict(raw, &natCfg); err != nil {
 		return nil, errors.Wrapf(err, "failed to parse NAT config YAML: %s", configPath)
 	}
 
@@ -67,7 +71,8 @@ func LoadNATConfigFromFile(configPath string) (*NATConfig, error) {
 
 // ParseNATConfigFromYAML 从YAML字节流解析NAT配置
 //
-// 直接从字节数组解析YAML配置，适用于配置已经在内存中的场景。
+// 直接从字节数组严格解析YAML配置，适用于配置已经在内存中的场景。
+// 与LoadNATConfigFromFile一样拒绝未知字段。
 //
 // 参数：
 //   - yamlData: YAML格式的配置数据
@@ -87,7 +92,7 @@ func LoadNATConfigFromFile(configPath string) (*NATConfig, error) {
 //	natCfg, err := ParseNATConfigFromYAML(yamlData)
 func ParseNATConfigFromYAML(yamlData []byte) (*NATConfig, error) {
 	var natCfg NATConfig
-	if err := yaml.Unmarshal(yamlData, &natCfg); err != nil {
+	if err := decodeStrict(yamlData, &natCfg); err != nil {
 		return nil, errors.Wrap(err, "failed to parse NAT config YAML")
 	}
 
@@ -97,11 +102,38 @@ func ParseNATConfigFromYAML(yamlData []byte) (*NATConfig, error) {
 	return &natCfg, nil
 }
 
+// decodeStrict 严格解析YAML
+//
+// 先解析为节点树，对照NATConfig的yaml标签检查未知字段，
+// 发现未知字段时返回包含全部未知字段的ValidationErrors（CodeUnknownField），
+// 每条错误带有所在的行号和列号；之后再将节点树解码到目标结构体，
+// 类型错误（如端口写成字符串）由yaml.v3返回，同样带有行号。
+func decodeStrict(data []byte, out *NATConfig) error {
+	var root yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&root); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil // 空文件，由验证报告缺失的必填字段
+		}
+		return err
+	}
+
+	v := &validator{}
+	checkUnknownFields(v, &root, reflect.TypeOf(*out), "")
+	if err := v.err(); err != nil {
+		return err
+	}
+
+	return root.Decode(out)
+}
+
 // applyDefaults 为NAT配置应用默认值
 //
 // 对于可选字段，如果用户未提供，则使用默认值：
 // - PortRange: 1024-65535
 // - Timeouts: VPP默认超时值
+// - DefaultAction: snat（全部连接做SNAT，与引入规则动作之前的行为一致）；SnatRules[].Action: snat
+// - NAT64.Prefix: 64:ff9b::/96
 // - Labels: 空map
 func applyDefaults(cfg *NATConfig) {
 	// 应用默认端口范围
@@ -137,6 +169,30 @@ func applyDefaults(cfg *NATConfig) {
 		}
 	}
 
+	// 应用默认SNAT动作
+	if cfg.DefaultAction == "" {
+		cfg.DefaultAction = SNATActionSNAT
+	}
+	for i := range cfg.SnatRules {
+		if cfg.SnatRules[i].Action == "" {
+			cfg.SnatRules[i].Action = SNATActionSNAT
+		}
+	}
+
+	// 应用默认后端权重
+	for i := range cfg.LBRules {
+		for j := range cfg.LBRules[i].Backends {
+			if cfg.LBRules[i].Backends[j].Weight == 0 {
+				cfg.LBRules[i].Backends[j].Weight = DefaultLBWeight
+			}
+		}
+	}
+
+	// 应用默认NAT64前缀
+	if cfg.NAT64 != nil && cfg.NAT64.Prefix == "" {
+		cfg.NAT64.Prefix = WellKnownNAT64Prefix
+	}
+
 	// 初始化空的Labels map
 	if cfg.Labels == nil {
 		cfg.Labels = make(map[string]string)
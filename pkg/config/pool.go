@@ -0,0 +1,359 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultPoolName 由natIP/natPool组成的默认地址池名称
+const DefaultPoolName = "default"
+
+// AddressRange 连续的IPv4地址范围
+//
+// 对应VPP Nat44AddDelAddressRange的FirstIPAddress/LastIPAddress,
+// 单个地址时First与Last相同。
+type AddressRange struct {
+	// First 起始地址
+	First net.IP
+
+	// Last 结束地址(包含)
+	Last net.IP
+
+	// VrfID 地址绑定的VRF(0为默认VRF)
+	VrfID uint32
+}
+
+// String 返回"a.b.c.d-e.f.g.h"格式,单个地址时只返回该地址
+func (r AddressRange) String() string {
+	if r.First.Equal(r.Last) {
+		return r.First.String()
+	}
+	return fmt.Sprintf("%s-%s", r.First, r.Last)
+}
+
+// Size 返回范围内的地址数量
+func (r AddressRange) Size() int {
+	return int(ipToUint32(r.Last)-ipToUint32(r.First)) + 1
+}
+
+// Addresses 返回范围内的全部地址
+func (r AddressRange) Addresses() []net.IP {
+	first, last := ipToUint32(r.First), ipToUint32(r.Last)
+	ips := make([]net.IP, 0, r.Size())
+	for v := first; v >= first && v <= last; v++ {
+		ips = append(ips, uint32ToIP(v))
+	}
+	return ips
+}
+
+// Contains 判断地址是否在范围内
+func (r AddressRange) Contains(ip net.IP) bool {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return false
+	}
+	v := ipToUint32(ip4)
+	return v >= ipToUint32(r.First) && v <= ipToUint32(r.Last)
+}
+
+// Overlaps 判断两个范围是否有重叠
+func (r AddressRange) Overlaps(other AddressRange) bool {
+	return ipToUint32(r.First) <= ipToUint32(other.Last) && ipToUint32(other.First) <= ipToUint32(r.Last)
+}
+
+// ParseAddressRange 解析地址池条目
+//
+// 支持三种格式:
+//   - 单个地址: "203.0.113.10"
+//   - 地址范围: "203.0.113.10-203.0.113.20"
+//   - CIDR: "203.0.113.0/28"(不包含网络地址和广播地址,/31和/32除外)
+//
+// 示例:
+//
+//	r, err := config.ParseAddressRange("198.51.100.0/29")
+//	// r.First = 198.51.100.1, r.Last = 198.51.100.6
+func ParseAddressRange(entry string) (AddressRange, error) {
+	entry = strings.TrimSpace(entry)
+
+	if strings.Contains(entry, "/") {
+		ip, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return AddressRange{}, fmt.Errorf("invalid CIDR '%s': %v", entry, err)
+		}
+		if ip.To4() == nil {
+			return AddressRange{}, fmt.Errorf("CIDR must be IPv4: %s", entry)
+		}
+		if !ip.Equal(ipNet.IP) {
+			return AddressRange{}, fmt.Errorf("CIDR '%s' has host bits set, expected %s", entry, ipNet)
+		}
+
+		network, broadcast := cidrBounds(ipNet)
+		ones, _ := ipNet.Mask.Size()
+		if ones >= 31 {
+			return AddressRange{First: network, Last: broadcast}, nil
+		}
+		return AddressRange{
+			First: uint32ToIP(ipToUint32(network) + 1),
+			Last:  uint32ToIP(ipToUint32(broadcast) - 1),
+		}, nil
+	}
+
+	if first, last, ok := strings.Cut(entry, "-"); ok {
+		firstIP := net.ParseIP(strings.TrimSpace(first)).To4()
+		lastIP := net.ParseIP(strings.TrimSpace(last)).To4()
+		if firstIP == nil || lastIP == nil {
+			return AddressRange{}, fmt.Errorf("invalid IPv4 address range '%s'", entry)
+		}
+		if ipToUint32(firstIP) > ipToUint32(lastIP) {
+			return AddressRange{}, fmt.Errorf("address range '%s' start must be <= end", entry)
+		}
+		return AddressRange{First: firstIP, Last: lastIP}, nil
+	}
+
+	ip := net.ParseIP(entry).To4()
+	if ip == nil {
+		return AddressRange{}, fmt.Errorf("invalid IPv4 address '%s'", entry)
+	}
+	return AddressRange{First: ip, Last: ip}, nil
+}
+
+// PoolRanges 返回SNAT地址池的全部地址范围
+//
+// 包含natPool中的每个条目;natIP已被natPool覆盖时不重复返回,
+// 否则作为单地址范围排在最前面。
+//
+// 返回:
+//   - []AddressRange: 地址范围列表
+//   - error: 条目解析错误
+func (c *NATConfig) PoolRanges() ([]AddressRange, error) {
+	ranges := make([]AddressRange, 0, len(c.NatPool)+1)
+	for i, entry := range c.NatPool {
+		r, err := ParseAddressRange(entry)
+		if err != nil {
+			return nil, fmt.Errorf("natPool[%d]: %v", i, err)
+		}
+		ranges = append(ranges, r)
+	}
+
+	if c.NatIP == "" {
+		return ranges, nil
+	}
+
+	natIP := net.ParseIP(c.NatIP).To4()
+	if natIP == nil {
+		return nil, fmt.Errorf("natIP is not a valid IPv4 address: %s", c.NatIP)
+	}
+	for _, r := range ranges {
+		if r.Contains(natIP) {
+			return ranges, nil
+		}
+	}
+
+	return append([]AddressRange{{First: natIP, Last: natIP}}, ranges...), nil
+}
+
+// Ranges 返回命名地址池的全部地址范围
+func (p *NATPool) Ranges() ([]AddressRange, error) {
+	ranges := make([]AddressRange, 0, len(p.Addresses))
+	for i, entry := range p.Addresses {
+		r, err := ParseAddressRange(entry)
+		if err != nil {
+			return nil, fmt.Errorf("pools[%s].addresses[%d]: %v", p.Name, i, err)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// TwiceNATRanges 返回twice-NAT地址池的全部地址范围
+func (c *NATConfig) TwiceNATRanges() ([]AddressRange, error) {
+	ranges := make([]AddressRange, 0, len(c.TwiceNATPool))
+	for i, entry := range c.TwiceNATPool {
+		r, err := ParseAddressRange(entry)
+		if err != nil {
+			return nil, fmt.Errorf("twiceNATPool[%d]: %v", i, err)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// Ranges 返回NAT64地址池的全部地址范围
+func (n *NAT64Config) Ranges() ([]AddressRange, error) {
+	ranges := make([]AddressRange, 0, len(n.Pool))
+	for i, entry := range n.Pool {
+		r, err := ParseAddressRange(entry)
+		if err != nil {
+			return nil, fmt.Errorf("nat64.pool[%d]: %v", i, err)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// MergeRanges 合并重叠或相邻的地址范围
+//
+// 只合并同一VRF内的范围,结果按VRF和起始地址排序。
+//
+// 示例:
+//
+//	config.MergeRanges(ranges) // 10.0.0.1, 10.0.0.2-10.0.0.5 → 10.0.0.1-10.0.0.5
+func MergeRanges(ranges []AddressRange) []AddressRange {
+	sorted := make([]AddressRange, 0, len(ranges))
+	for _, r := range ranges {
+		if r.First.To4() == nil || r.Last.To4() == nil || ipToUint32(r.First) > ipToUint32(r.Last) {
+			continue
+		}
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].VrfID != sorted[j].VrfID {
+			return sorted[i].VrfID < sorted[j].VrfID
+		}
+		return ipToUint32(sorted[i].First) < ipToUint32(sorted[j].First)
+	})
+
+	merged := make([]AddressRange, 0, len(sorted))
+	for _, r := range sorted {
+		if n := len(merged); n > 0 {
+			prev := &merged[n-1]
+			if prev.VrfID == r.VrfID && uint64(ipToUint32(r.First)) <= uint64(ipToUint32(prev.Last))+1 {
+				if ipToUint32(r.Last) > ipToUint32(prev.Last) {
+					prev.Last = r.Last
+				}
+				continue
+			}
+		}
+		merged = append(merged, AddressRange{First: r.First, Last: r.Last, VrfID: r.VrfID})
+	}
+	return merged
+}
+
+// SubtractRanges 返回ranges中不被other覆盖的地址,按MergeRanges的形式返回
+func SubtractRanges(ranges, other []AddressRange) []AddressRange {
+	other = MergeRanges(other)
+
+	var result []AddressRange
+	for _, r := range MergeRanges(ranges) {
+		first, last := uint64(ipToUint32(r.First)), uint64(ipToUint32(r.Last))
+		for _, o := range other {
+			if o.VrfID != r.VrfID || first > last {
+				continue
+			}
+			oFirst, oLast := uint64(ipToUint32(o.First)), uint64(ipToUint32(o.Last))
+			if oLast < first || oFirst > last {
+				continue
+			}
+			if oFirst > first {
+				result = append(result, AddressRange{First: uint32ToIP(uint32(first)), Last: uint32ToIP(uint32(oFirst - 1)), VrfID: r.VrfID})
+			}
+			first = oLast + 1
+		}
+		if first <= last {
+			result = append(result, AddressRange{First: uint32ToIP(uint32(first)), Last: uint32ToIP(uint32(last)), VrfID: r.VrfID})
+		}
+	}
+	return result
+}
+
+// IntersectRanges 返回同时被a和b覆盖的地址,按MergeRanges的形式返回
+func IntersectRanges(a, b []AddressRange) []AddressRange {
+	return SubtractRanges(a, SubtractRanges(a, b))
+}
+
+// FindPool 按名称查找命名地址池,不存在时返回nil
+func (c *NATConfig) FindPool(name string) *NATPool {
+	for i := range c.Pools {
+		if c.Pools[i].Name == name {
+			return &c.Pools[i]
+		}
+	}
+	return nil
+}
+
+// SelectPool 为客户端选择地址池
+//
+// 按顺序匹配PoolSelectors,返回第一个命中的命名地址池;
+// 没有命中时返回nil,表示使用默认地址池(natIP/natPool)。
+//
+// 参数:
+//   - labels: 客户端NetworkServiceRequest标签
+//   - spiffeID: 客户端SPIFFE ID(未知时为空)
+//
+// 示例:
+//
+//	pool := natCfg.SelectPool(map[string]string{"podName": "alpine"}, "")
+//	if pool == nil {
+//	    // 使用默认地址池
+//	}
+func (c *NATConfig) SelectPool(labels map[string]string, spiffeID string) *NATPool {
+	for _, selector := range c.PoolSelectors {
+		if selector.Matches(labels, spiffeID) {
+			return c.FindPool(selector.Pool)
+		}
+	}
+	return nil
+}
+
+// Matches 判断客户端是否满足选择器的全部条件
+func (s *PoolSelector) Matches(labels map[string]string, spiffeID string) bool {
+	for k, v := range s.MatchLabels {
+		if labels[k] != v {
+			return false
+		}
+	}
+
+	if s.SpiffeID != "" {
+		if spiffeID == "" {
+			return false
+		}
+		if ok, err := path.Match(s.SpiffeID, spiffeID); err != nil || !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cidrBounds 返回CIDR的网络地址和广播地址
+func cidrBounds(ipNet *net.IPNet) (network, broadcast net.IP) {
+	network = ipNet.IP.To4()
+	mask := binary.BigEndian.Uint32(net.IP(ipNet.Mask).To4())
+	return network, uint32ToIP(ipToUint32(network) | ^mask)
+}
+
+// ipToUint32 将IPv4地址转换为整数,便于比较
+func ipToUint32(ip net.IP) uint32 {
+	return binary.BigEndian.Uint32(ip.To4())
+}
+
+// uint32ToIP 将整数转换为IPv4地址
+func uint32ToIP(v uint32) net.IP {
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, v)
+	return ip
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// parseRanges 解析地址池条目,结果均在默认VRF
+func parseRanges(t *testing.T, entries ...string) []config.AddressRange {
+	ranges := make([]config.AddressRange, 0, len(entries))
+	for _, entry := range entries {
+		r, err := config.ParseAddressRange(entry)
+		require.NoError(t, err)
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+func rangeStrings(ranges []config.AddressRange) string {
+	s := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		s = append(s, r.String())
+	}
+	return strings.Join(s, ",")
+}
+
+func TestMergeRanges(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		ranges []string
+		want   string
+	}{
+		{name: "empty"},
+		{name: "adjacent", ranges: []string{"10.0.0.3", "10.0.0.1", "10.0.0.2"}, want: "10.0.0.1-10.0.0.3"},
+		{name: "overlapping", ranges: []string{"10.0.0.0-10.0.0.3", "10.0.0.2-10.0.0.9"}, want: "10.0.0.0-10.0.0.9"},
+		{name: "gap", ranges: []string{"10.0.0.1", "10.0.0.3"}, want: "10.0.0.1,10.0.0.3"},
+		{name: "address space end", ranges: []string{"255.255.255.254", "255.255.255.255"}, want: "255.255.255.254-255.255.255.255"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, rangeStrings(config.MergeRanges(parseRanges(t, tc.ranges...))))
+		})
+	}
+}
+
+func TestMergeRanges_KeepsVRFsApart(t *testing.T) {
+	ranges := parseRanges(t, "10.0.0.1", "10.0.0.2")
+	ranges[1].VrfID = 5
+
+	merged := config.MergeRanges(ranges)
+	require.Len(t, merged, 2, "不同VRF的相邻地址不应合并")
+	require.Equal(t, uint32(0), merged[0].VrfID)
+	require.Equal(t, uint32(5), merged[1].VrfID)
+}
+
+func TestSubtractRanges(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		ranges []string
+		other  []string
+		want   string
+	}{
+		{name: "nothing to subtract", ranges: []string{"10.0.0.0-10.0.0.3"}, want: "10.0.0.0-10.0.0.3"},
+		{name: "hole", ranges: []string{"10.0.0.0-10.0.0.7"}, other: []string{"10.0.0.3-10.0.0.4"}, want: "10.0.0.0-10.0.0.2,10.0.0.5-10.0.0.7"},
+		{name: "prefix and suffix", ranges: []string{"10.0.0.0-10.0.0.7"}, other: []string{"10.0.0.0", "10.0.0.7"}, want: "10.0.0.1-10.0.0.6"},
+		{name: "covered", ranges: []string{"10.0.0.1-10.0.0.2"}, other: []string{"10.0.0.0-10.0.0.255"}},
+		{name: "address space end", ranges: []string{"255.255.255.0-255.255.255.255"}, other: []string{"255.255.255.255"}, want: "255.255.255.0-255.255.255.254"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := config.SubtractRanges(parseRanges(t, tc.ranges...), parseRanges(t, tc.other...))
+			require.Equal(t, tc.want, rangeStrings(got))
+		})
+	}
+}
+
+func TestIntersectRanges(t *testing.T) {
+	got := config.IntersectRanges(
+		parseRanges(t, "10.0.0.0-10.0.0.7", "10.0.1.1"),
+		parseRanges(t, "10.0.0.6-10.0.1.0", "10.0.0.2"),
+	)
+	require.Equal(t, "10.0.0.2,10.0.0.6-10.0.0.7", rangeStrings(got))
+}
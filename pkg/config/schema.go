@@ -0,0 +1,37 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	_ "embed"
+)
+
+// NATConfigSchema NAT配置文件的JSON Schema（draft 2020-12）
+//
+// 供编辑器补全和CI预检使用，与NATConfig的yaml标签保持一致。
+// Schema只描述单个字段的格式，字段之间的约束（如地址池重叠）仍由ValidateNATConfig检查。
+//
+// 示例（VS Code yaml插件）：
+//
+//	# yaml-language-server: $schema=./nat-config.schema.json
+//
+//go:embed nat-config.schema.json
+var NATConfigSchema []byte
@@ -0,0 +1,310 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// snatAddress SNAT使用的地址范围及其来源
+type snatAddress struct {
+	// field 定义该范围的字段路径
+	field string
+
+	// pool 所属地址池名称
+	pool string
+
+	// r 地址范围
+	r AddressRange
+}
+
+// validateSemantics 检查字段之间的关系
+//
+// 单个字段格式错误的值会被跳过，由字段级验证报告。
+// 重复的SNAT源网段是错误，其余发现作为警告返回：
+//   - SNAT源网段被同动作的更宽网段覆盖（不同动作的嵌套网段按最长前缀匹配，是预期用法）
+//   - natIP或地址池地址位于SNAT源网段内
+//   - DNAT（含负载均衡规则）externalIP既不是natIP也不在地址池中
+//   - 配置了非默认的portRange（nat44-ed不支持，不会生效）
+//   - DNAT externalPort落在动态SNAT端口范围（1024-65535）内，可能与动态SNAT端口冲突
+//   - DNAT internalIP或负载均衡后端地址未被任何SNAT规则覆盖，或命中drop规则
+//   - 启用了hairpinning但没有DNAT规则
+//   - NAT豁免的地址本来就不做转换（见validateExemptionRelations，其中的矛盾作为错误返回）
+func validateSemantics(v *validator, cfg *NATConfig) {
+	nets := make([]*net.IPNet, len(cfg.SnatRules))
+	for i, rule := range cfg.SnatRules {
+		if _, ipNet, err := net.ParseCIDR(rule.SrcNet); err == nil {
+			nets[i] = ipNet
+		}
+	}
+
+	validateSNATOverlaps(v, cfg.SnatRules, nets)
+
+	addrs := snatAddresses(cfg)
+	validateSNATAddresses(v, cfg, addrs, nets)
+	validateDNATRelations(v, cfg, addrs)
+
+	validateExemptionRelations(v, cfg)
+
+	if cfg.Hairpinning && len(cfg.DnatRules) == 0 {
+		v.warnf("hairpinning", CodeNoEffect, "hairpinning has no effect without dnatRules")
+	}
+
+	if pr, def := cfg.PortRange, DefaultPortRange(); pr != nil && pr.Start <= pr.End && *pr != *def {
+		v.warnf("portRange", CodeNoEffect, "nat44-ed has no port range setting and allocates dynamic SNAT ports from %d-%d, portRange %d-%d is not applied",
+			def.Start, def.End, pr.Start, pr.End)
+	}
+}
+
+// validateSNATOverlaps 检查重复和被覆盖的SNAT源网段
+func validateSNATOverlaps(v *validator, rules []SNATRule, nets []*net.IPNet) {
+	for j, netJ := range nets {
+		if netJ == nil {
+			continue
+		}
+		field := fmt.Sprintf("snatRules[%d].srcNet", j)
+		onesJ, _ := netJ.Mask.Size()
+
+		duplicate := false
+		parent := -1
+		parentOnes := -1
+		for i, netI := range nets {
+			if i == j || netI == nil || len(netI.IP) != len(netJ.IP) {
+				continue
+			}
+			onesI, _ := netI.Mask.Size()
+			if onesI == onesJ && netI.IP.Equal(netJ.IP) {
+				if i < j {
+					v.addf(field, CodeDuplicate, "'%s' is already defined by snatRules[%d] ('%s')", rules[j].SrcNet, i, rules[i].SrcNet)
+					duplicate = true
+					break
+				}
+				continue
+			}
+			if onesI < onesJ && netI.Contains(netJ.IP) && onesI > parentOnes {
+				parent, parentOnes = i, onesI
+			}
+		}
+
+		if !duplicate && parent >= 0 && snatAction(rules[parent]) == snatAction(rules[j]) {
+			v.warnf(field, CodeShadowed, "'%s' is covered by snatRules[%d] ('%s') with the same action '%s', the rule has no effect",
+				rules[j].SrcNet, parent, rules[parent].SrcNet, snatAction(rules[j]))
+		}
+	}
+}
+
+// validateSNATAddresses 检查natIP和地址池地址是否位于SNAT源网段内
+//
+// 这样的地址会同时出现在inside侧和outside侧，转换后的报文无法与内部主机区分。
+func validateSNATAddresses(v *validator, cfg *NATConfig, addrs []snatAddress, nets []*net.IPNet) {
+	for _, addr := range addrs {
+		var inside []string
+		for i, ipNet := range nets {
+			if ipNet == nil || ipNet.IP.To4() == nil {
+				continue
+			}
+			network, broadcast := cidrBounds(ipNet)
+			if addr.r.Overlaps(AddressRange{First: network, Last: broadcast}) {
+				inside = append(inside, fmt.Sprintf("snatRules[%d] ('%s')", i, cfg.SnatRules[i].SrcNet))
+			}
+		}
+		if len(inside) > 0 {
+			v.warnf(addr.field, CodeSelfTranslated, "SNAT address %s of pool '%s' is inside the source network of %s",
+				addr.r, addr.pool, strings.Join(inside, ", "))
+		}
+	}
+}
+
+// validateDNATRelations 检查DNAT规则和负载均衡规则与natIP、地址池、端口范围和SNAT规则的关系
+func validateDNATRelations(v *validator, cfg *NATConfig, addrs []snatAddress) {
+	for i, rule := range cfg.DnatRules {
+		field := fmt.Sprintf("dnatRules[%d]", i)
+		extStart, extEnd := rule.ExternalPorts()
+		validateExternalEndpoint(v, addrs, field, rule.ExternalIP, extStart, extEnd)
+		validateInternalAddress(v, cfg, field+".internalIP", "internalIP", rule.InternalIP)
+	}
+
+	for i, rule := range cfg.LBRules {
+		field := fmt.Sprintf("lbRules[%d]", i)
+		validateExternalEndpoint(v, addrs, field, rule.ExternalIP, rule.ExternalPort, rule.ExternalPort)
+		for j, backend := range rule.Backends {
+			validateInternalAddress(v, cfg, fmt.Sprintf("%s.backends[%d].ip", field, j), "backend", backend.IP)
+		}
+	}
+}
+
+// validateExternalEndpoint 检查外部地址是否属于SNAT地址，以及外部端口start-end是否与动态SNAT端口范围重叠
+//
+// start为0表示地址一对一映射，它占用地址上的全部端口，只要外部地址是SNAT地址就会冲突。
+func validateExternalEndpoint(v *validator, addrs []snatAddress, field, ip string, start, end uint16) {
+	externalIP := net.ParseIP(ip).To4()
+	if externalIP == nil {
+		return
+	}
+
+	owner := -1
+	for k, addr := range addrs {
+		if addr.r.Contains(externalIP) {
+			owner = k
+			break
+		}
+	}
+
+	dynamic := DefaultPortRange()
+	switch {
+	case owner < 0:
+		v.warnf(field+".externalIP", CodeNotInPool, "externalIP %s is neither natIP nor in natPool or pools, traffic to it may not reach the NSE",
+			ip)
+	case start == 0:
+		v.warnf(field+".externalIP", CodePortConflict, "address-only mapping takes all ports of %s and collides with dynamic SNAT on it (%s, pool '%s')",
+			ip, addrs[owner].field, addrs[owner].pool)
+	case start == end && start >= dynamic.Start && start <= dynamic.End:
+		v.warnf(field+".externalPort", CodePortConflict, "externalPort %d is inside the dynamic SNAT port range %d-%d and may collide with dynamic SNAT ports of %s (%s, pool '%s')",
+			start, dynamic.Start, dynamic.End, ip, addrs[owner].field, addrs[owner].pool)
+	case start <= dynamic.End && end >= dynamic.Start:
+		v.warnf(field+".externalPort", CodePortConflict, "external ports %d-%d overlap the dynamic SNAT port range %d-%d and may collide with dynamic SNAT ports of %s (%s, pool '%s')",
+			start, end, dynamic.Start, dynamic.End, ip, addrs[owner].field, addrs[owner].pool)
+	}
+}
+
+// validateInternalAddress 检查内部地址是否被SNAT规则覆盖且未命中drop规则，name为消息中的地址名称
+func validateInternalAddress(v *validator, cfg *NATConfig, field, name, ip string) {
+	internalIP := net.ParseIP(ip).To4()
+	if internalIP == nil {
+		return
+	}
+
+	action, snatRule := cfg.MatchSNATAction(internalIP)
+	switch {
+	case snatRule == nil && action != SNATActionSNAT:
+		v.warnf(field, CodeNotCovered, "%s %s is not covered by any snatRules.srcNet, defaultAction '%s' applies",
+			name, ip, action)
+	case action == SNATActionDrop:
+		v.warnf(field, CodeNotCovered, "%s %s matches snatRules[%d] ('%s') with action 'drop'",
+			name, ip, snatRuleIndex(cfg, snatRule), snatRule.SrcNet)
+	}
+}
+
+// validateExemptionRelations 检查NAT豁免与SNAT规则和DNAT规则的关系
+//
+// 命中drop的地址、落在DNAT规则内部端点上或地址一对一映射内部地址上的豁免无法生效，作为错误返回；
+// 按forward规则或默认动作本来就不做转换的地址豁免不起作用，作为警告返回。
+func validateExemptionRelations(v *validator, cfg *NATConfig) {
+	for i, e := range cfg.Exemptions {
+		field := fmt.Sprintf("exemptions[%d]", i)
+		ip := net.ParseIP(e.IP).To4()
+		if ip == nil {
+			continue
+		}
+
+		action, snatRule := cfg.MatchSNATAction(ip)
+		switch {
+		case action == SNATActionDrop && snatRule != nil:
+			v.addf(field+".ip", CodeConflict, "%s matches snatRules[%d] ('%s') with action 'drop', the client is rejected and cannot be exempted",
+				e.IP, snatRuleIndex(cfg, snatRule), snatRule.SrcNet)
+		case action == SNATActionDrop:
+			v.addf(field+".ip", CodeConflict, "%s is not covered by any snatRules.srcNet and defaultAction is 'drop', the client is rejected and cannot be exempted",
+				e.IP)
+		case snatRule == nil && action == SNATActionForward:
+			v.warnf(field+".ip", CodeNotCovered, "%s is not covered by any snatRules.srcNet and is forwarded untranslated (defaultAction '%s'), the exemption has no effect",
+				e.IP, action)
+		case action == SNATActionForward:
+			v.warnf(field+".ip", CodeNoEffect, "%s matches snatRules[%d] ('%s') with action 'forward', the exemption has no effect",
+				e.IP, snatRuleIndex(cfg, snatRule), snatRule.SrcNet)
+		}
+
+		for j, rule := range cfg.DnatRules {
+			if rule.InternalIP != e.IP {
+				continue
+			}
+			if rule.IsAddressOnly() {
+				v.addf(field, CodeConflict, "'%s' is the internal address of address-only dnatRules[%d] (%s)", e, j, rule)
+			} else if e.Port != 0 && dnatCoversInternalPort(rule, e.Protocol, e.Port) {
+				v.addf(field, CodeConflict, "'%s' is an internal endpoint of dnatRules[%d] (%s)", e, j, rule)
+			}
+		}
+	}
+}
+
+// dnatCoversInternalPort 判断DNAT规则的内部端口范围是否包含protocol的port
+func dnatCoversInternalPort(rule DNATRule, protocol string, port uint16) bool {
+	start, end := rule.InternalPorts()
+	if port < start || port > end {
+		return false
+	}
+	for _, p := range rule.Protocols() {
+		if strings.EqualFold(p, protocol) {
+			return true
+		}
+	}
+	return false
+}
+
+// snatAddresses 返回natIP、natPool和命名地址池中的全部地址范围，格式错误的条目被跳过
+func snatAddresses(cfg *NATConfig) []snatAddress {
+	var addrs []snatAddress
+
+	for i, entry := range cfg.NatPool {
+		if r, err := ParseAddressRange(entry); err == nil {
+			addrs = append(addrs, snatAddress{field: fmt.Sprintf("natPool[%d]", i), pool: DefaultPoolName, r: r})
+		}
+	}
+	// natIP已被natPool覆盖时不重复计入，与PoolRanges一致
+	if natIP := net.ParseIP(cfg.NatIP).To4(); natIP != nil {
+		covered := false
+		for _, addr := range addrs {
+			covered = covered || addr.r.Contains(natIP)
+		}
+		if !covered {
+			addrs = append([]snatAddress{{field: "natIP", pool: DefaultPoolName, r: AddressRange{First: natIP, Last: natIP}}}, addrs...)
+		}
+	}
+	for i := range cfg.Pools {
+		pool := &cfg.Pools[i]
+		for j, entry := range pool.Addresses {
+			if r, err := ParseAddressRange(entry); err == nil {
+				addrs = append(addrs, snatAddress{field: fmt.Sprintf("pools[%d].addresses[%d]", i, j), pool: pool.Name, r: r})
+			}
+		}
+	}
+
+	return addrs
+}
+
+// snatAction 返回规则的动作，未设置时为snat
+func snatAction(rule SNATRule) string {
+	if rule.Action == "" {
+		return SNATActionSNAT
+	}
+	return rule.Action
+}
+
+// snatRuleIndex 返回规则在SnatRules中的下标
+func snatRuleIndex(cfg *NATConfig, rule *SNATRule) int {
+	for i := range cfg.SnatRules {
+		if &cfg.SnatRules[i] == rule {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+func TestCheckNATConfig_Warnings(t *testing.T) {
+	cfg := baseNATConfig()
+	cfg.PortRange = config.DefaultPortRange()
+	cfg.DefaultAction = config.SNATActionForward
+	cfg.SnatRules = []config.SNATRule{
+		{SrcNet: "10.0.0.0/8"},
+		{SrcNet: "10.1.0.0/16"},
+		{SrcNet: "10.2.0.0/16", Action: config.SNATActionForward},
+		{SrcNet: "203.0.113.0/24", Action: config.SNATActionForward},
+	}
+	cfg.DnatRules = []config.DNATRule{
+		{ExternalIP: "198.51.100.1", ExternalPort: 80, InternalIP: "10.0.1.100", InternalPort: 80, Protocol: "tcp"},
+		{ExternalIP: "203.0.113.10", ExternalPort: 8080, InternalIP: "192.168.1.1", InternalPort: 8080, Protocol: "tcp"},
+	}
+
+	warnings, err := config.CheckNATConfig(cfg)
+	require.NoError(t, err, "警告不应使验证失败")
+	require.ElementsMatch(t, []string{
+		"snatRules[1].srcNet " + config.CodeShadowed,
+		"natIP " + config.CodeSelfTranslated,
+		"dnatRules[0].externalIP " + config.CodeNotInPool,
+		"dnatRules[1].externalPort " + config.CodePortConflict,
+		"dnatRules[1].internalIP " + config.CodeNotCovered,
+	}, fieldCodes(warnings), "不同动作的嵌套网段不应报告为被覆盖")
+
+	for _, w := range warnings {
+		require.Equal(t, config.SeverityWarning, w.Severity)
+		if w.Field == "dnatRules[1].externalPort" {
+			require.Contains(t, w.Message, "203.0.113.10 (natIP, pool 'default')", "警告应指明冲突的地址来源")
+		}
+		if w.Field == "snatRules[1].srcNet" {
+			require.Contains(t, w.Message, "snatRules[0]")
+		}
+	}
+}
+
+func TestCheckNATConfig_RelationWarnings(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		modify func(cfg *config.NATConfig)
+		want   []string
+	}{
+		{
+			name:   "port range not supported by nat44-ed",
+			modify: func(cfg *config.NATConfig) { cfg.PortRange = &config.PortRange{Start: 10000, End: 20000} },
+			want:   []string{"portRange " + config.CodeNoEffect},
+		},
+		{
+			name:   "default port range",
+			modify: func(cfg *config.NATConfig) { cfg.PortRange = config.DefaultPortRange() },
+		},
+		{
+			name:   "hairpinning without dnat",
+			modify: func(cfg *config.NATConfig) { cfg.Hairpinning = true },
+			want:   []string{"hairpinning " + config.CodeNoEffect},
+		},
+		{
+			name: "address-only mapping on natIP",
+			modify: func(cfg *config.NATConfig) {
+				cfg.DnatRules = []config.DNATRule{{ExternalIP: "203.0.113.10", InternalIP: "10.0.1.100"}}
+			},
+			want: []string{"dnatRules[0].externalIP " + config.CodePortConflict},
+		},
+		{
+			name: "lb backend dropped",
+			modify: func(cfg *config.NATConfig) {
+				cfg.SnatRules = append(cfg.SnatRules, config.SNATRule{SrcNet: "10.9.0.0/16", Action: config.SNATActionDrop})
+				cfg.LBRules = []config.LBRule{{
+					ExternalIP: "203.0.113.10", ExternalPort: 80, Protocol: "tcp",
+					Backends: []config.LBBackend{{IP: "10.0.1.1", Port: 80}, {IP: "10.9.0.1", Port: 80}},
+				}}
+			},
+			want: []string{"lbRules[0].backends[1].ip " + config.CodeNotCovered},
+		},
+		{
+			name: "exemption of forwarded address",
+			modify: func(cfg *config.NATConfig) {
+				cfg.DefaultAction = config.SNATActionForward
+				cfg.Exemptions = []config.Exemption{{IP: "192.168.1.1"}}
+			},
+			want: []string{"exemptions[0].ip " + config.CodeNotCovered},
+		},
+		{
+			name: "exemption of address translated by default",
+			modify: func(cfg *config.NATConfig) {
+				cfg.Exemptions = []config.Exemption{{IP: "192.168.1.1"}}
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := baseNATConfig()
+			tc.modify(cfg)
+			warnings, err := config.CheckNATConfig(cfg)
+			require.NoError(t, err)
+			require.ElementsMatch(t, tc.want, fieldCodes(warnings))
+		})
+	}
+}
+
+func TestCheckNATConfig_ExemptionConflicts(t *testing.T) {
+	cfg := baseNATConfig()
+	cfg.SnatRules = append(cfg.SnatRules, config.SNATRule{SrcNet: "10.9.0.0/16", Action: config.SNATActionDrop})
+	cfg.DnatRules = []config.DNATRule{{ExternalIP: "203.0.113.10", ExternalPort: 80, InternalIP: "10.0.1.100", InternalPort: 8080, Protocol: "tcp"}}
+	cfg.Exemptions = []config.Exemption{
+		{IP: "10.9.0.1"},
+		{IP: "10.0.1.100", Port: 8080, Protocol: "tcp"},
+	}
+
+	_, err := config.CheckNATConfig(cfg)
+	require.ElementsMatch(t, []string{
+		"exemptions[0].ip " + config.CodeConflict,
+		"exemptions[1] " + config.CodeConflict,
+	}, errorCodes(t, err), "无法生效的豁免应作为错误返回")
+}
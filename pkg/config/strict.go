@@ -0,0 +1,136 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkUnknownFields 对照结构体的yaml标签检查节点树中的未知字段
+//
+// 每个未知字段记录一条CodeUnknownField错误，带有字段路径、行号和列号；
+// 能找到近似的已知字段（大小写或单复数不同）时给出提示。
+// 节点类型与结构体不符的情况不在此处报告，由yaml解码返回类型错误。
+func checkUnknownFields(v *validator, node *yaml.Node, t reflect.Type, path string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			checkUnknownFields(v, child, t, path)
+		}
+		return
+	case yaml.AliasNode:
+		checkUnknownFields(v, node.Alias, t, path)
+		return
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		names, fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Tag == "!!merge" {
+				checkUnknownFields(v, value, t, path)
+				continue
+			}
+			field, ok := fields[key.Value]
+			if !ok {
+				msg := "unknown field"
+				if hint := suggestField(key.Value, names); hint != "" {
+					msg += fmt.Sprintf(", did you mean '%s'?", hint)
+				}
+				v.errs = append(v.errs, &FieldError{
+					Field:    joinFieldPath(path, key.Value),
+					Code:     CodeUnknownField,
+					Message:  msg,
+					Severity: SeverityError,
+					Line:     key.Line,
+					Column:   key.Column,
+				})
+				continue
+			}
+			checkUnknownFields(v, value, field.Type, joinFieldPath(path, key.Value))
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			checkUnknownFields(v, item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			checkUnknownFields(v, node.Content[i+1], t.Elem(), joinFieldPath(path, node.Content[i].Value))
+		}
+	}
+}
+
+// yamlFields 返回结构体按声明顺序的yaml字段名，以及字段名到字段的映射
+func yamlFields(t reflect.Type) (names []string, fields map[string]reflect.StructField) {
+	fields = make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = strings.ToLower(field.Name)
+		}
+		names = append(names, name)
+		fields[name] = field
+	}
+	return names, fields
+}
+
+// suggestField 返回与key只有大小写或单复数差异的已知字段名，没有时返回空字符串
+func suggestField(key string, names []string) string {
+	for _, name := range names {
+		if strings.EqualFold(name, key) || strings.EqualFold(name, key+"s") || strings.EqualFold(name+"s", key) {
+			return name
+		}
+	}
+	return ""
+}
+
+// joinFieldPath 拼接字段路径，如"portRange"+"start"得到"portRange.start"
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
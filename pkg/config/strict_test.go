@@ -0,0 +1,99 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+func TestParseNATConfigFromYAML_UnknownFields(t *testing.T) {
+	data := []byte(`name: nat-nse
+natIP: "203.0.113.10"
+snatRule:
+  - srcNet: "10.0.0.0/8"
+dnatRules:
+  - externalIP: "203.0.113.10"
+    externalPort: 80
+    internalIP: "10.0.1.100"
+    internalPort: 8080
+    protocl: tcp
+timeouts:
+  UDP: 300
+`)
+
+	_, err := config.ParseNATConfigFromYAML(data)
+	var verrs config.ValidationErrors
+	require.True(t, errors.As(err, &verrs), "应返回ValidationErrors, got: %v", err)
+	require.Len(t, verrs, 3, "应报告全部未知字段")
+
+	for i, want := range []struct {
+		field  string
+		line   int
+		column int
+		hint   string
+	}{
+		{field: "snatRule", line: 3, column: 1, hint: "did you mean 'snatRules'?"},
+		{field: "dnatRules[0].protocl", line: 10, column: 5},
+		{field: "timeouts.UDP", line: 12, column: 3, hint: "did you mean 'udp'?"},
+	} {
+		fe := verrs[i]
+		require.Equal(t, want.field, fe.Field)
+		require.Equal(t, config.CodeUnknownField, fe.Code)
+		require.Equal(t, want.line, fe.Line)
+		require.Equal(t, want.column, fe.Column)
+		if want.hint != "" {
+			require.Contains(t, fe.Message, want.hint)
+		} else {
+			require.NotContains(t, fe.Message, "did you mean")
+		}
+	}
+	require.Contains(t, verrs[0].Error(), "(line 3, column 1)")
+}
+
+func TestParseNATConfigFromYAML_TypeError(t *testing.T) {
+	data := []byte(`name: nat-nse
+natIP: "203.0.113.10"
+portRange:
+  start: low
+snatRules:
+  - srcNet: "10.0.0.0/8"
+`)
+
+	_, err := config.ParseNATConfigFromYAML(data)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "line 4")
+}
+
+func TestParseNATConfigFromYAML_KnownFields(t *testing.T) {
+	cfg, err := config.ParseNATConfigFromYAML([]byte(`name: nat-nse
+natIP: "203.0.113.10"
+snatRules:
+  - srcNet: "10.0.0.0/8"
+    action: snat
+`))
+	require.NoError(t, err, "已知字段不应报错")
+	require.NoError(t, config.ValidateNATConfig(cfg))
+}
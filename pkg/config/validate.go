@@ -23,6 +23,7 @@ package config
 import (
 	"fmt"
 	"net"
+	"path"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -31,17 +32,25 @@ import (
 // ValidateNATConfig 验证NAT配置的完整性和有效性
 //
 // 实现data-model.md和nat-config-schema.yaml中定义的所有验证规则：
-//   - 必填字段检查（name, natIP, snatRules）
+//   - 必填字段检查（name, natIP、natPool、nat64或nptv6, snatRules）
+//   - 地址池格式与重叠检查
 //   - IP地址格式验证
 //   - 端口范围验证
 //   - CIDR格式验证
 //   - 协议枚举验证
+//   - twice-NAT地址池及DNAT规则对它的引用
+//   - NAT64前缀和地址池验证
+//   - NPTv6前缀格式及内外前缀长度一致
+//   - 字段之间的关系（如重复的SNAT源网段）
+//
+// 所有规则都会被检查，不会在第一个错误处停止。
+// 不影响配置生效的语义问题只作为警告，由CheckNATConfig返回。
 //
 // 参数：
 //   - cfg: 待验证的NAT配置
 //
 // 返回：
-//   - error: 第一个发现的验证错误，如果配置有效则返回nil
+//   - error: 配置有效时为nil，否则为包含全部错误的ValidationErrors
 //
 // 示例：
 //
@@ -50,205 +59,680 @@ import (
 //	    log.Fatalf("Invalid NAT config: %v", err)
 //	}
 func ValidateNATConfig(cfg *NATConfig) error {
+	_, err := CheckNATConfig(cfg)
+	return err
+}
+
+// CheckNATConfig 验证NAT配置，并返回字段之间组合可能不符合预期的警告
+//
+// 错误与ValidateNATConfig相同；警告（Severity为SeverityWarning）不会使验证失败，
+// 每条警告都指明涉及的规则，例如DNAT外部端口落在SNAT动态端口范围内：
+//
+//	dnatRules[0].externalPort: externalPort 8080 is inside the dynamic SNAT port range 1024-65535 and may collide
+//	with dynamic SNAT ports of 203.0.113.10 (natIP, pool 'default')
+//
+// 返回：
+//   - ValidationErrors: 全部警告
+//   - error: 配置有效时为nil，否则为包含全部错误的ValidationErrors
+//
+// 示例：
+//
+//	warnings, err := config.CheckNATConfig(natCfg)
+//	for _, w := range warnings {
+//	    log.Warnf("NAT config: %v [%s]", w, w.Code)
+//	}
+func CheckNATConfig(cfg *NATConfig) (ValidationErrors, error) {
 	if cfg == nil {
-		return errors.New("NAT config cannot be nil")
+		return nil, errors.New("NAT config cannot be nil")
 	}
 
+	v := &validator{}
+
 	// 验证必填字段
-	if err := validateRequiredFields(cfg); err != nil {
-		return err
-	}
+	validateRequiredFields(v, cfg)
 
 	// 验证natIP格式
-	if err := validateIPAddress(cfg.NatIP, "natIP"); err != nil {
-		return err
+	if cfg.NatIP != "" {
+		validateIPAddress(v, cfg.NatIP, "natIP")
 	}
 
+	// 验证地址池
+	validateNATPool(v, cfg.NatPool, "natPool")
+
+	// 验证命名地址池和选择器
+	validatePools(v, cfg)
+
 	// 验证端口范围
-	if err := validatePortRange(cfg.PortRange); err != nil {
-		return err
-	}
+	validatePortRange(v, cfg.PortRange)
 
 	// 验证SNAT规则
-	if err := validateSNATRules(cfg.SnatRules); err != nil {
-		return err
+	validateSNATRules(v, cfg.SnatRules)
+
+	// 验证未匹配源地址的默认动作
+	switch cfg.DefaultAction {
+	case "", SNATActionSNAT, SNATActionForward, SNATActionDrop:
+	default:
+		v.addf("defaultAction", CodeInvalidValue, "must be one of '%s', '%s', '%s', got: '%s'",
+			SNATActionSNAT, SNATActionForward, SNATActionDrop, cfg.DefaultAction)
 	}
 
 	// 验证DNAT规则（如果存在）
-	if len(cfg.DnatRules) > 0 {
-		if err := validateDNATRules(cfg.DnatRules); err != nil {
-			return err
-		}
-	}
+	endpoints := validateDNATRules(v, cfg.DnatRules)
+
+	// 验证twice-NAT地址池及其引用
+	validateTwiceNAT(v, cfg)
+
+	// 验证负载均衡DNAT规则（如果存在）
+	validateLBRules(v, cfg, endpoints)
+
+	// 验证NAT豁免（如果存在）
+	validateExemptions(v, cfg.Exemptions)
 
 	// 验证超时配置（如果存在）
-	if cfg.Timeouts != nil {
-		if err := validateTimeouts(cfg.Timeouts); err != nil {
-			return err
-		}
-	}
+	validateTimeouts(v, cfg.Timeouts)
+
+	// 验证NAT64配置（如果存在）
+	validateNAT64(v, cfg)
+
+	// 验证NPTv6规则（如果存在）
+	validateNPTv6Rules(v, cfg.NPTv6)
 
-	return nil
+	// 检查字段之间的关系
+	validateSemantics(v, cfg)
+
+	return v.warnings, v.err()
 }
 
 // validateRequiredFields 验证必填字段
-func validateRequiredFields(cfg *NATConfig) error {
+func validateRequiredFields(v *validator, cfg *NATConfig) {
 	if cfg.Name == "" {
-		return errors.New("field 'name' is required")
-	}
-
-	if cfg.NatIP == "" {
-		return errors.New("field 'natIP' is required")
+		v.addf("name", CodeRequired, "field is required")
 	}
 
-	if len(cfg.SnatRules) == 0 {
-		return errors.New("field 'snatRules' must contain at least one rule")
+	if cfg.NatIP == "" && len(cfg.NatPool) == 0 && cfg.NAT64 == nil && len(cfg.NPTv6) == 0 {
+		v.addf("natIP", CodeRequired, "field 'natIP', 'natPool', 'nat64' or 'nptv6' is required")
 	}
-
-	return nil
 }
 
-// validateIPAddress 验证IP地址格式
-func validateIPAddress(ipStr string, fieldName string) error {
+// validateIPAddress 验证IPv4地址格式，有效时返回true
+func validateIPAddress(v *validator, ipStr string, fieldName string) bool {
 	ip := net.ParseIP(ipStr)
 	if ip == nil {
-		return fmt.Errorf("field '%s' has invalid IP address format: %s", fieldName, ipStr)
+		v.addf(fieldName, CodeInvalidIP, "invalid IP address format: '%s'", ipStr)
+		return false
 	}
 
 	// 验证是IPv4地址
 	if ip.To4() == nil {
-		return fmt.Errorf("field '%s' must be an IPv4 address: %s", fieldName, ipStr)
+		v.addf(fieldName, CodeNotIPv4, "must be an IPv4 address: %s", ipStr)
+		return false
+	}
+
+	return true
+}
+
+// validateNATPool 验证SNAT地址池
+//
+// 检查每个条目的格式、条目之间是否重叠，
+// 以及是否有条目包含了某个CIDR条目的网络地址或广播地址。
+func validateNATPool(v *validator, pool []string, field string) {
+	ranges := make([]*AddressRange, len(pool))
+	for i, entry := range pool {
+		r, err := ParseAddressRange(entry)
+		if err != nil {
+			v.addf(fmt.Sprintf("%s[%d]", field, i), CodeInvalidAddressRange, "%v", err)
+			continue
+		}
+		ranges[i] = &r
+	}
+
+	for i := range ranges {
+		for j := i + 1; j < len(ranges); j++ {
+			if ranges[i] != nil && ranges[j] != nil && ranges[i].Overlaps(*ranges[j]) {
+				v.addf(fmt.Sprintf("%s[%d]", field, i), CodeOverlap, "'%s' overlaps %s[%d] ('%s')", pool[i], field, j, pool[j])
+			}
+		}
 	}
 
-	return nil
+	// CIDR的网络地址和广播地址不能作为SNAT地址
+	for i, entry := range pool {
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			continue
+		}
+		if ones, _ := ipNet.Mask.Size(); ones >= 31 {
+			continue
+		}
+		network, broadcast := cidrBounds(ipNet)
+		for j, r := range ranges {
+			if r != nil && (r.Contains(network) || r.Contains(broadcast)) {
+				v.addf(fmt.Sprintf("%s[%d]", field, j), CodeInvalidAddressRange, "'%s' includes network or broadcast address of %s[%d] ('%s')", pool[j], field, i, entry)
+			}
+		}
+	}
+}
+
+// validatePools 验证命名地址池和地址池选择器
+func validatePools(v *validator, cfg *NATConfig) {
+	names := make(map[string]bool)
+	var all []AddressRange
+	var owners []string
+
+	if defaultRanges, err := cfg.PoolRanges(); err == nil {
+		all = append(all, defaultRanges...)
+		for range defaultRanges {
+			owners = append(owners, DefaultPoolName)
+		}
+	}
+
+	for i := range cfg.Pools {
+		pool := &cfg.Pools[i]
+		field := fmt.Sprintf("pools[%d]", i)
+
+		switch {
+		case pool.Name == "":
+			v.addf(field+".name", CodeRequired, "field is required")
+		case pool.Name == DefaultPoolName:
+			v.addf(field+".name", CodeInvalidValue, "name '%s' is reserved", pool.Name)
+		case names[pool.Name]:
+			v.addf(field+".name", CodeDuplicate, "name '%s' is duplicated", pool.Name)
+		}
+		names[pool.Name] = true
+
+		if len(pool.Addresses) == 0 {
+			v.addf(field+".addresses", CodeRequired, "must contain at least one entry")
+			continue
+		}
+		before := len(v.errs)
+		validateNATPool(v, pool.Addresses, field+".addresses")
+		if len(v.errs) > before {
+			continue
+		}
+
+		// 不同地址池之间地址不能重叠
+		ranges, _ := pool.Ranges()
+		for _, r := range ranges {
+			for j, other := range all {
+				if r.Overlaps(other) {
+					v.addf(field+".addresses", CodeOverlap, "address %s of pool '%s' overlaps pool '%s' address %s", r, pool.Name, owners[j], other)
+				}
+			}
+			all = append(all, r)
+			owners = append(owners, pool.Name)
+		}
+	}
+
+	for i, selector := range cfg.PoolSelectors {
+		field := fmt.Sprintf("poolSelectors[%d]", i)
+		if cfg.FindPool(selector.Pool) == nil {
+			v.addf(field+".pool", CodeUnknownReference, "references unknown pool '%s'", selector.Pool)
+		}
+		if len(selector.MatchLabels) == 0 && selector.SpiffeID == "" {
+			v.addf(field, CodeRequired, "must set matchLabels or spiffeID")
+		}
+		if selector.SpiffeID != "" {
+			if _, err := path.Match(selector.SpiffeID, ""); err != nil {
+				v.addf(field+".spiffeID", CodeInvalidPattern, "invalid pattern '%s': %v", selector.SpiffeID, err)
+			}
+		}
+	}
 }
 
 // validatePortRange 验证端口范围配置
-func validatePortRange(pr *PortRange) error {
+func validatePortRange(v *validator, pr *PortRange) {
 	if pr == nil {
-		return nil // 端口范围是可选的
+		return // 端口范围是可选的
 	}
 
-	// 验证端口值在有效范围内
-	if pr.Start == 0 || pr.Start > 65535 {
-		return fmt.Errorf("portRange.start must be between 1 and 65535, got: %d", pr.Start)
+	// 验证端口值在有效范围内（uint16不会超过65535）
+	if pr.Start == 0 {
+		v.addf("portRange.start", CodeOutOfRange, "must be between 1 and 65535, got: %d", pr.Start)
 	}
 
-	if pr.End == 0 || pr.End > 65535 {
-		return fmt.Errorf("portRange.end must be between 1 and 65535, got: %d", pr.End)
+	if pr.End == 0 {
+		v.addf("portRange.end", CodeOutOfRange, "must be between 1 and 65535, got: %d", pr.End)
 	}
 
 	// 验证start <= end
 	if pr.Start > pr.End {
-		return fmt.Errorf("portRange.start (%d) must be <= portRange.end (%d)", pr.Start, pr.End)
+		v.addf("portRange", CodeOutOfRange, "start (%d) must be <= end (%d)", pr.Start, pr.End)
 	}
-
-	return nil
 }
 
 // validateSNATRules 验证SNAT规则列表
-func validateSNATRules(rules []SNATRule) error {
+func validateSNATRules(v *validator, rules []SNATRule) {
 	if len(rules) == 0 {
-		return errors.New("snatRules cannot be empty")
+		v.addf("snatRules", CodeRequired, "must contain at least one rule")
+		return
 	}
 
 	for i, rule := range rules {
-		if err := validateSNATRule(rule, i); err != nil {
-			return err
-		}
+		validateSNATRule(v, rule, i)
 	}
-
-	return nil
 }
 
 // validateSNATRule 验证单条SNAT规则
-func validateSNATRule(rule SNATRule, index int) error {
+func validateSNATRule(v *validator, rule SNATRule, index int) {
+	field := fmt.Sprintf("snatRules[%d]", index)
+
 	if rule.SrcNet == "" {
-		return fmt.Errorf("snatRules[%d].srcNet is required", index)
+		v.addf(field+".srcNet", CodeRequired, "field is required")
+	} else if _, _, err := net.ParseCIDR(rule.SrcNet); err != nil {
+		// 验证CIDR格式
+		v.addf(field+".srcNet", CodeInvalidCIDR, "invalid CIDR format '%s': %v", rule.SrcNet, err)
 	}
 
-	// 验证CIDR格式
-	_, _, err := net.ParseCIDR(rule.SrcNet)
-	if err != nil {
-		return fmt.Errorf("snatRules[%d].srcNet has invalid CIDR format '%s': %v", index, rule.SrcNet, err)
+	// 验证动作
+	switch rule.Action {
+	case "", SNATActionSNAT, SNATActionForward, SNATActionDrop:
+	default:
+		v.addf(field+".action", CodeInvalidValue, "must be one of '%s', '%s', '%s', got: '%s'",
+			SNATActionSNAT, SNATActionForward, SNATActionDrop, rule.Action)
 	}
-
-	return nil
 }
 
 // validateDNATRules 验证DNAT规则列表
-func validateDNATRules(rules []DNATRule) error {
-	// 用于检测端口冲突
-	externalEndpoints := make(map[string]bool)
+//
+// 规则按协议和端口展开后检查冲突：同一外部端点不能映射到多个内部服务，
+// 同一内部端点也不能被多条规则映射；地址一对一映射占用地址上的全部端口。
+// 返回dnatRules占用的外部端点，供validateLBRules继续检查。
+func validateDNATRules(v *validator, rules []DNATRule) *dnatEndpoints {
+	external := newDNATEndpoints()
+	internal := newDNATEndpoints()
+	// 外部端 → 首个定义它的规则，用于区分完全重复和部分重叠
+	seen := make(map[string]int)
 
 	for i, rule := range rules {
-		if err := validateDNATRule(rule, i); err != nil {
-			return err
+		field := fmt.Sprintf("dnatRules[%d]", i)
+		validateDNATRule(v, rule, i)
+
+		extStart, extEnd := rule.ExternalPorts()
+		key := rule.ExternalIP
+		if !rule.IsAddressOnly() {
+			key = strings.ToLower(rule.Protocol) + " " + formatPortSpan(rule.ExternalIP, extStart, extEnd)
+		}
+		if first, ok := seen[key]; ok {
+			v.addf(field, CodeDuplicate, "duplicate DNAT mapping for %s, already defined by dnatRules[%d]", key, first)
+			continue
 		}
+		seen[key] = i
 
-		// 检测DNAT规则冲突（同一个externalIP:port不能映射到多个内部服务）
-		endpoint := fmt.Sprintf("%s:%s:%d", strings.ToLower(rule.Protocol), rule.ExternalIP, rule.ExternalPort)
-		if externalEndpoints[endpoint] {
-			return fmt.Errorf("dnatRules[%d]: duplicate DNAT mapping for %s:%d (protocol: %s)", i, rule.ExternalIP, rule.ExternalPort, rule.Protocol)
+		if endpoint, owner := external.claim(field, rule.ExternalIP, rule.Protocols(), extStart, extEnd); owner != "" {
+			v.addf(field, CodeOverlap, "%s overlaps the external endpoints of %s", endpoint, owner)
+		}
+		intStart, intEnd := rule.InternalPorts()
+		if endpoint, owner := internal.claim(field, rule.InternalIP, rule.Protocols(), intStart, intEnd); owner != "" {
+			v.addf(field, CodeOverlap, "%s overlaps the internal endpoints of %s", endpoint, owner)
 		}
-		externalEndpoints[endpoint] = true
 	}
 
-	return nil
+	return external
 }
 
 // validateDNATRule 验证单条DNAT规则
-func validateDNATRule(rule DNATRule, index int) error {
+//
+// 地址一对一映射不能设置协议；其余规则的端口范围须有效且内外大小相同，
+// 协议为tcp、udp或both。
+func validateDNATRule(v *validator, rule DNATRule, index int) {
+	field := fmt.Sprintf("dnatRules[%d]", index)
+
 	// 验证externalIP
-	if err := validateIPAddress(rule.ExternalIP, fmt.Sprintf("dnatRules[%d].externalIP", index)); err != nil {
-		return err
-	}
+	validateIPAddress(v, rule.ExternalIP, field+".externalIP")
 
 	// 验证internalIP
-	if err := validateIPAddress(rule.InternalIP, fmt.Sprintf("dnatRules[%d].internalIP", index)); err != nil {
-		return err
-	}
+	validateIPAddress(v, rule.InternalIP, field+".internalIP")
 
-	// 验证端口
-	if rule.ExternalPort == 0 || rule.ExternalPort > 65535 {
-		return fmt.Errorf("dnatRules[%d].externalPort must be between 1 and 65535, got: %d", index, rule.ExternalPort)
+	// 地址一对一映射转换全部协议（包括ICMP），不能再限定协议
+	if rule.IsAddressOnly() {
+		if rule.Protocol != "" {
+			v.addf(field+".protocol", CodeInvalidValue, "must be omitted for address-only rules (no ports), got: '%s'", rule.Protocol)
+		}
+		return
 	}
 
-	if rule.InternalPort == 0 || rule.InternalPort > 65535 {
-		return fmt.Errorf("dnatRules[%d].internalPort must be between 1 and 65535, got: %d", index, rule.InternalPort)
+	// 验证端口范围
+	extOK := validateDNATPorts(v, field, "externalPort", rule.ExternalPort, rule.ExternalPortEnd)
+	intOK := validateDNATPorts(v, field, "internalPort", rule.InternalPort, rule.InternalPortEnd)
+	if extOK && intOK {
+		extStart, extEnd := rule.ExternalPorts()
+		intStart, intEnd := rule.InternalPorts()
+		if extEnd-extStart != intEnd-intStart {
+			v.addf(field+".internalPortEnd", CodeRangeSizeMismatch, "internal ports %d-%d (%d ports) must match the size of external ports %d-%d (%d ports)",
+				intStart, intEnd, int(intEnd-intStart)+1, extStart, extEnd, int(extEnd-extStart)+1)
+		}
 	}
 
 	// 验证协议
 	protocol := strings.ToLower(rule.Protocol)
-	if protocol != "tcp" && protocol != "udp" {
-		return fmt.Errorf("dnatRules[%d].protocol must be 'tcp' or 'udp', got: '%s'", index, rule.Protocol)
+	if protocol != "tcp" && protocol != "udp" && protocol != DNATProtocolBoth {
+		v.addf(field+".protocol", CodeInvalidValue, "must be 'tcp', 'udp' or '%s', got: '%s'", DNATProtocolBoth, rule.Protocol)
 	}
+}
+
+// validateDNATPorts 验证起始端口和可选的结束端口，name为起始端口的字段名
+func validateDNATPorts(v *validator, field, name string, start, end uint16) bool {
+	// uint16不会超过65535
+	if start == 0 {
+		v.addf(field+"."+name, CodeOutOfRange, "must be between 1 and 65535, got: %d", start)
+		return false
+	}
+	if end != 0 && end < start {
+		v.addf(field+"."+name+"End", CodeOutOfRange, "must not be less than %s %d, got: %d", name, start, end)
+		return false
+	}
+	return true
+}
 
-	return nil
+// dnatPort 展开后的单个DNAT端点
+type dnatPort struct {
+	protocol string
+	ip       string
+	port     uint16
+}
+
+// dnatEndpoints DNAT规则展开后占用的端点，用于检测规则之间的重叠
+type dnatEndpoints struct {
+	ports   map[dnatPort]string // 端点 → 占用它的规则字段
+	addrs   map[string]string   // 地址一对一映射占用的地址 → 规则字段
+	portIPs map[string]string   // 有端口映射的地址 → 首个占用它的规则字段
+}
+
+// newDNATEndpoints 创建空的端点集合
+func newDNATEndpoints() *dnatEndpoints {
+	return &dnatEndpoints{
+		ports:   make(map[dnatPort]string),
+		addrs:   make(map[string]string),
+		portIPs: make(map[string]string),
+	}
+}
+
+// claim 为规则field占用ip上各协议的端口范围，start为0时占用整个地址
+//
+// 与已占用的端点冲突时不占用任何端点，返回首个冲突的端点和占用它的规则字段。
+func (e *dnatEndpoints) claim(field, ip string, protocols []string, start, end uint16) (endpoint, owner string) {
+	if owner, ok := e.addrs[ip]; ok {
+		return ip, owner
+	}
+	if start == 0 {
+		if owner, ok := e.portIPs[ip]; ok {
+			return ip, owner
+		}
+		e.addrs[ip] = field
+		return "", ""
+	}
+
+	for _, protocol := range protocols {
+		for port := uint32(start); port <= uint32(end); port++ {
+			if owner, ok := e.ports[dnatPort{protocol: protocol, ip: ip, port: uint16(port)}]; ok {
+				return fmt.Sprintf("%s %s:%d", protocol, ip, port), owner
+			}
+		}
+	}
+	for _, protocol := range protocols {
+		for port := uint32(start); port <= uint32(end); port++ {
+			e.ports[dnatPort{protocol: protocol, ip: ip, port: uint16(port)}] = field
+		}
+	}
+	if _, ok := e.portIPs[ip]; !ok {
+		e.portIPs[ip] = field
+	}
+	return "", ""
+}
+
+// validateTwiceNAT 验证twice-NAT地址池
+//
+// 地址池格式同natPool；twiceNAT与selfTwiceNAT互斥，
+// 启用其中任一项的DNAT规则要求配置了twiceNATPool。
+func validateTwiceNAT(v *validator, cfg *NATConfig) {
+	validateNATPool(v, cfg.TwiceNATPool, "twiceNATPool")
+
+	for i, rule := range cfg.DnatRules {
+		field := fmt.Sprintf("dnatRules[%d]", i)
+		if rule.TwiceNAT && rule.SelfTwiceNAT {
+			v.addf(field, CodeInvalidValue, "twiceNAT and selfTwiceNAT are mutually exclusive")
+		}
+		if len(cfg.TwiceNATPool) > 0 {
+			continue
+		}
+		if rule.TwiceNAT {
+			v.addf(field+".twiceNAT", CodeRequired, "requires twiceNATPool")
+		}
+		if rule.SelfTwiceNAT {
+			v.addf(field+".selfTwiceNAT", CodeRequired, "requires twiceNATPool")
+		}
+	}
+}
+
+// validateLBRules 验证负载均衡DNAT规则
+//
+// 外部端点的检查同dnatRules，且不能与dnatRules或其他lbRules的外部端点重复；
+// 每条规则至少有2个后端，同一规则内的后端不能重复。
+func validateLBRules(v *validator, cfg *NATConfig, endpoints *dnatEndpoints) {
+	for i := range cfg.LBRules {
+		rule := &cfg.LBRules[i]
+		field := fmt.Sprintf("lbRules[%d]", i)
+
+		validateIPAddress(v, rule.ExternalIP, field+".externalIP")
+		if rule.ExternalPort == 0 {
+			v.addf(field+".externalPort", CodeOutOfRange, "must be between 1 and 65535, got: %d", rule.ExternalPort)
+		}
+		protocol := strings.ToLower(rule.Protocol)
+		if protocol != "tcp" && protocol != "udp" {
+			v.addf(field+".protocol", CodeInvalidValue, "must be 'tcp' or 'udp', got: '%s'", rule.Protocol)
+		}
+
+		if rule.ExternalPort != 0 {
+			if endpoint, owner := endpoints.claim(field, rule.ExternalIP, []string{protocol}, rule.ExternalPort, rule.ExternalPort); owner != "" {
+				v.addf(field, CodeDuplicate, "duplicate DNAT mapping for %s, already defined by %s", endpoint, owner)
+			}
+		}
+
+		if len(rule.Backends) < 2 {
+			v.addf(field+".backends", CodeOutOfRange, "must list at least 2 backends, got: %d (use dnatRules for a single backend)", len(rule.Backends))
+		}
+		for j, backend := range rule.Backends {
+			backendField := fmt.Sprintf("%s.backends[%d]", field, j)
+			validateIPAddress(v, backend.IP, backendField+".ip")
+			if backend.Port == 0 {
+				v.addf(backendField+".port", CodeOutOfRange, "must be between 1 and 65535, got: %d", backend.Port)
+			}
+			if first := rule.FindBackend(backend.IP, backend.Port); first < j {
+				v.addf(backendField, CodeDuplicate, "backend %s:%d is already listed as backends[%d]", backend.IP, backend.Port, first)
+			}
+		}
+	}
+}
+
+// validateExemptions 验证NAT豁免
+//
+// 设置port时protocol必填且为tcp或udp，未设置port时不能设置protocol；
+// 同一豁免不能重复，整个地址已被豁免时不能再单独豁免该地址的端口。
+func validateExemptions(v *validator, exemptions []Exemption) {
+	seen := make(map[string]int)
+	addrOnly := make(map[string]int)
+
+	for i, e := range exemptions {
+		field := fmt.Sprintf("exemptions[%d]", i)
+		validateIPAddress(v, e.IP, field+".ip")
+
+		if e.Port == 0 {
+			if e.Protocol != "" {
+				v.addf(field+".port", CodeRequired, "required when protocol is set")
+			}
+		} else if protocol := strings.ToLower(e.Protocol); protocol != "tcp" && protocol != "udp" {
+			v.addf(field+".protocol", CodeInvalidValue, "must be 'tcp' or 'udp' when port is set, got: '%s'", e.Protocol)
+		}
+
+		if first, ok := seen[e.String()]; ok {
+			v.addf(field, CodeDuplicate, "'%s' is already exempted by exemptions[%d]", e, first)
+			continue
+		}
+		seen[e.String()] = i
+		if e.Port == 0 {
+			addrOnly[e.IP] = i
+		}
+	}
+
+	for i, e := range exemptions {
+		if first, ok := addrOnly[e.IP]; ok && e.Port != 0 {
+			v.addf(fmt.Sprintf("exemptions[%d]", i), CodeOverlap, "'%s' is covered by exemptions[%d], which exempts %s as a whole", e, first, e.IP)
+		}
+	}
 }
 
 // validateTimeouts 验证NAT超时配置
-func validateTimeouts(timeouts *NATTimeouts) error {
+func validateTimeouts(v *validator, timeouts *NATTimeouts) {
 	if timeouts == nil {
-		return nil
+		return
 	}
 
-	// 所有超时值必须>0
+	// 已设置的超时值不能过小
 	if timeouts.TcpEstablished > 0 && timeouts.TcpEstablished < 60 {
-		return fmt.Errorf("timeouts.tcpEstablished should be >= 60 seconds, got: %d", timeouts.TcpEstablished)
+		v.addf("timeouts.tcpEstablished", CodeOutOfRange, "should be >= 60 seconds, got: %d", timeouts.TcpEstablished)
 	}
 
 	if timeouts.TcpTransitory > 0 && timeouts.TcpTransitory < 30 {
-		return fmt.Errorf("timeouts.tcpTransitory should be >= 30 seconds, got: %d", timeouts.TcpTransitory)
+		v.addf("timeouts.tcpTransitory", CodeOutOfRange, "should be >= 30 seconds, got: %d", timeouts.TcpTransitory)
 	}
 
 	if timeouts.Udp > 0 && timeouts.Udp < 30 {
-		return fmt.Errorf("timeouts.udp should be >= 30 seconds, got: %d", timeouts.Udp)
+		v.addf("timeouts.udp", CodeOutOfRange, "should be >= 30 seconds, got: %d", timeouts.Udp)
 	}
 
 	if timeouts.Icmp > 0 && timeouts.Icmp < 10 {
-		return fmt.Errorf("timeouts.icmp should be >= 10 seconds, got: %d", timeouts.Icmp)
+		v.addf("timeouts.icmp", CodeOutOfRange, "should be >= 10 seconds, got: %d", timeouts.Icmp)
+	}
+}
+
+// nat64PrefixLengths RFC 6052允许的NAT64前缀长度
+var nat64PrefixLengths = map[int]bool{32: true, 40: true, 48: true, 56: true, 64: true, 96: true}
+
+// validateNAT64 验证NAT64配置
+//
+// 前缀必须是RFC 6052允许长度的IPv6前缀；地址池格式同natPool，
+// 且不能与natIP、natPool和命名地址池重叠。
+// 没有action为snat的IPv6 SNAT规则时，任何客户端都不会启用NAT64，作为警告报告。
+func validateNAT64(v *validator, cfg *NATConfig) {
+	if cfg.NAT64 == nil {
+		return
+	}
+
+	if cfg.NAT64.Prefix != "" {
+		ip, ipNet, err := net.ParseCIDR(cfg.NAT64.Prefix)
+		switch {
+		case err != nil:
+			v.addf("nat64.prefix", CodeInvalidCIDR, "invalid CIDR format '%s': %v", cfg.NAT64.Prefix, err)
+		case ip.To4() != nil:
+			v.addf("nat64.prefix", CodeInvalidCIDR, "must be an IPv6 prefix: %s", cfg.NAT64.Prefix)
+		default:
+			if ones, _ := ipNet.Mask.Size(); !nat64PrefixLengths[ones] {
+				v.addf("nat64.prefix", CodeOutOfRange, "prefix length must be 32, 40, 48, 56, 64 or 96, got: %d", ones)
+			}
+		}
+	}
+
+	if len(cfg.NAT64.Pool) == 0 {
+		v.addf("nat64.pool", CodeRequired, "must contain at least one entry")
+		return
+	}
+	before := len(v.errs)
+	validateNAT64Pool(v, cfg)
+	if len(v.errs) > before {
+		return
 	}
 
-	return nil
+	hasIPv6Rule := false
+	for _, rule := range cfg.SnatRules {
+		ip, _, err := net.ParseCIDR(rule.SrcNet)
+		if err == nil && ip.To4() == nil && snatAction(rule) == SNATActionSNAT {
+			hasIPv6Rule = true
+		}
+	}
+	if !hasIPv6Rule {
+		v.warnf("nat64", CodeNotCovered, "no snatRules entry with an IPv6 srcNet and action '%s', NAT64 is not used by any client", SNATActionSNAT)
+	}
+}
+
+// validateNAT64Pool 验证NAT64地址池格式及其与SNAT地址池的重叠
+func validateNAT64Pool(v *validator, cfg *NATConfig) {
+	validateNATPool(v, cfg.NAT64.Pool, "nat64.pool")
+
+	ranges, err := cfg.NAT64.Ranges()
+	if err != nil {
+		return
+	}
+	for _, addr := range snatAddresses(cfg) {
+		for i, r := range ranges {
+			if r.Overlaps(addr.r) {
+				v.addf(fmt.Sprintf("nat64.pool[%d]", i), CodeOverlap, "'%s' overlaps SNAT address %s (%s, pool '%s')",
+					cfg.NAT64.Pool[i], addr.r, addr.field, addr.pool)
+			}
+		}
+	}
+}
+
+// validateNPTv6Rules 验证NPTv6规则
+//
+// 内外前缀必须是不带主机位的IPv6前缀且长度相同；
+// 不同规则的内部前缀之间、外部前缀之间不能重叠。
+func validateNPTv6Rules(v *validator, rules []NPTv6Rule) {
+	insideNets := make([]*net.IPNet, len(rules))
+	outsideNets := make([]*net.IPNet, len(rules))
+
+	for i, rule := range rules {
+		field := fmt.Sprintf("nptv6[%d]", i)
+		insideNets[i] = validateIPv6Prefix(v, rule.InsidePrefix, field+".insidePrefix")
+		outsideNets[i] = validateIPv6Prefix(v, rule.OutsidePrefix, field+".outsidePrefix")
+
+		if insideNets[i] != nil && outsideNets[i] != nil {
+			insideLen, _ := insideNets[i].Mask.Size()
+			outsideLen, _ := outsideNets[i].Mask.Size()
+			if insideLen != outsideLen {
+				v.addf(field, CodePrefixLengthMismatch, "insidePrefix length /%d must match outsidePrefix length /%d", insideLen, outsideLen)
+			}
+		}
+	}
+
+	for i := range rules {
+		for j := i + 1; j < len(rules); j++ {
+			if prefixesOverlap(insideNets[i], insideNets[j]) {
+				v.addf(fmt.Sprintf("nptv6[%d].insidePrefix", j), CodeOverlap, "'%s' overlaps nptv6[%d].insidePrefix ('%s')",
+					rules[j].InsidePrefix, i, rules[i].InsidePrefix)
+			}
+			if prefixesOverlap(outsideNets[i], outsideNets[j]) {
+				v.addf(fmt.Sprintf("nptv6[%d].outsidePrefix", j), CodeOverlap, "'%s' overlaps nptv6[%d].outsidePrefix ('%s')",
+					rules[j].OutsidePrefix, i, rules[i].OutsidePrefix)
+			}
+		}
+	}
+}
+
+// validateIPv6Prefix 验证IPv6前缀，有效时返回解析结果
+func validateIPv6Prefix(v *validator, prefix, field string) *net.IPNet {
+	if prefix == "" {
+		v.addf(field, CodeRequired, "field is required")
+		return nil
+	}
+
+	ip, ipNet, err := net.ParseCIDR(prefix)
+	switch {
+	case err != nil:
+		v.addf(field, CodeInvalidCIDR, "invalid CIDR format '%s': %v", prefix, err)
+		return nil
+	case ip.To4() != nil:
+		v.addf(field, CodeInvalidCIDR, "must be an IPv6 prefix: %s", prefix)
+		return nil
+	case !ip.Equal(ipNet.IP):
+		v.addf(field, CodeInvalidCIDR, "'%s' has host bits set, expected %s", prefix, ipNet)
+		return nil
+	}
+	return ipNet
+}
+
+// prefixesOverlap 判断两个前缀是否重叠（任一为nil时返回false）
+func prefixesOverlap(a, b *net.IPNet) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	return a.Contains(b.IP) || b.Contains(a.IP)
 }
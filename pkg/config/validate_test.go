@@ -0,0 +1,331 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// baseNATConfig 返回一份没有错误和警告的最小NAT配置
+func baseNATConfig() *config.NATConfig {
+	return &config.NATConfig{
+		Name:      "nat-nse",
+		NatIP:     "203.0.113.10",
+		SnatRules: []config.SNATRule{{SrcNet: "10.0.0.0/8"}},
+	}
+}
+
+// fieldCodes 返回结果中每一项的"字段 错误码"
+func fieldCodes(errs config.ValidationErrors) []string {
+	codes := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		codes = append(codes, fe.Field+" "+fe.Code)
+	}
+	return codes
+}
+
+// errorCodes 从验证错误中取出ValidationErrors,返回每一项的"字段 错误码"
+func errorCodes(t *testing.T, err error) []string {
+	t.Helper()
+	var verrs config.ValidationErrors
+	require.True(t, errors.As(err, &verrs), "应返回ValidationErrors, got: %v", err)
+	for _, fe := range verrs {
+		require.Equal(t, config.SeverityError, fe.Severity)
+	}
+	return fieldCodes(verrs)
+}
+
+func TestValidateNATConfig_Valid(t *testing.T) {
+	warnings, err := config.CheckNATConfig(baseNATConfig())
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+}
+
+func TestValidateNATConfig_CollectsAllErrors(t *testing.T) {
+	cfg := &config.NATConfig{
+		NatIP:     "300.1.1.1",
+		PortRange: &config.PortRange{Start: 2000, End: 1000},
+		SnatRules: []config.SNATRule{
+			{SrcNet: "10.0.0.0/33"},
+			{SrcNet: "10.0.0.0/8", Action: "nat"},
+		},
+		DnatRules: []config.DNATRule{
+			{ExternalIP: "203.0.113.10", ExternalPort: 80, InternalIP: "10.0.1.100", InternalPort: 8080, Protocol: "icmp"},
+		},
+		Timeouts: &config.NATTimeouts{Udp: 5},
+	}
+
+	err := config.ValidateNATConfig(cfg)
+	require.ElementsMatch(t, []string{
+		"name " + config.CodeRequired,
+		"natIP " + config.CodeInvalidIP,
+		"portRange " + config.CodeOutOfRange,
+		"snatRules[0].srcNet " + config.CodeInvalidCIDR,
+		"snatRules[1].action " + config.CodeInvalidValue,
+		"dnatRules[0].protocol " + config.CodeInvalidValue,
+		"timeouts.udp " + config.CodeOutOfRange,
+	}, errorCodes(t, err), "不应在第一个错误处停止")
+	require.Contains(t, err.Error(), "7 validation errors")
+}
+
+func TestValidateNATConfig_FieldPaths(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		modify func(cfg *config.NATConfig)
+		want   []string
+	}{
+		{
+			name:   "natPool entry",
+			modify: func(cfg *config.NATConfig) { cfg.NatPool = []string{"198.51.100.1", "198.51.100.300"} },
+			want:   []string{"natPool[1] " + config.CodeInvalidAddressRange},
+		},
+		{
+			name:   "natPool overlap",
+			modify: func(cfg *config.NATConfig) { cfg.NatPool = []string{"198.51.100.1-198.51.100.5", "198.51.100.4"} },
+			want:   []string{"natPool[0] " + config.CodeOverlap},
+		},
+		{
+			name: "pool selector",
+			modify: func(cfg *config.NATConfig) {
+				cfg.PoolSelectors = []config.PoolSelector{{Pool: "missing", MatchLabels: map[string]string{"app": "x"}}}
+			},
+			want: []string{"poolSelectors[0].pool " + config.CodeUnknownReference},
+		},
+		{
+			name: "duplicate srcNet",
+			modify: func(cfg *config.NATConfig) {
+				cfg.SnatRules = append(cfg.SnatRules, config.SNATRule{SrcNet: "10.0.0.0/8"})
+			},
+			want: []string{"snatRules[1].srcNet " + config.CodeDuplicate},
+		},
+		{
+			name: "dnat port range size",
+			modify: func(cfg *config.NATConfig) {
+				cfg.DnatRules = []config.DNATRule{{
+					ExternalIP: "203.0.113.10", ExternalPort: 5000, ExternalPortEnd: 5009,
+					InternalIP: "10.0.1.100", InternalPort: 6000, InternalPortEnd: 6004, Protocol: "tcp",
+				}}
+			},
+			want: []string{"dnatRules[0].internalPortEnd " + config.CodeRangeSizeMismatch},
+		},
+		{
+			name: "lb backend",
+			modify: func(cfg *config.NATConfig) {
+				cfg.LBRules = []config.LBRule{{
+					ExternalIP: "203.0.113.10", ExternalPort: 80, Protocol: "tcp",
+					Backends: []config.LBBackend{{IP: "10.0.1.1", Port: 80}, {IP: "10.0.1.1", Port: 80}},
+				}}
+			},
+			want: []string{"lbRules[0].backends[1] " + config.CodeDuplicate},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := baseNATConfig()
+			tc.modify(cfg)
+			require.ElementsMatch(t, tc.want, errorCodes(t, config.ValidateNATConfig(cfg)))
+		})
+	}
+}
+
+func TestValidateNATConfig_NAT64(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		nat64    *config.NAT64Config
+		ipv6Rule bool
+		errs     []string
+		warnings []string
+	}{
+		{
+			name:     "valid",
+			nat64:    &config.NAT64Config{Prefix: "64:ff9b::/96", Pool: []string{"198.51.100.1-198.51.100.4"}},
+			ipv6Rule: true,
+		},
+		{
+			name:     "default prefix",
+			nat64:    &config.NAT64Config{Pool: []string{"198.51.100.1"}},
+			ipv6Rule: true,
+		},
+		{
+			name:     "prefix length",
+			nat64:    &config.NAT64Config{Prefix: "64:ff9b::/80", Pool: []string{"198.51.100.1"}},
+			ipv6Rule: true,
+			errs:     []string{"nat64.prefix " + config.CodeOutOfRange},
+		},
+		{
+			name:     "ipv4 prefix",
+			nat64:    &config.NAT64Config{Prefix: "10.0.0.0/8", Pool: []string{"198.51.100.1"}},
+			ipv6Rule: true,
+			errs:     []string{"nat64.prefix " + config.CodeInvalidCIDR},
+		},
+		{
+			name:     "empty pool",
+			nat64:    &config.NAT64Config{Prefix: "64:ff9b::/96"},
+			ipv6Rule: true,
+			errs:     []string{"nat64.pool " + config.CodeRequired},
+		},
+		{
+			name:     "pool overlaps natIP",
+			nat64:    &config.NAT64Config{Pool: []string{"203.0.113.8-203.0.113.15"}},
+			ipv6Rule: true,
+			errs:     []string{"nat64.pool[0] " + config.CodeOverlap},
+		},
+		{
+			name:     "no ipv6 snat rule",
+			nat64:    &config.NAT64Config{Pool: []string{"198.51.100.1"}},
+			warnings: []string{"nat64 " + config.CodeNotCovered},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := baseNATConfig()
+			cfg.NAT64 = tc.nat64
+			if tc.ipv6Rule {
+				cfg.SnatRules = append(cfg.SnatRules, config.SNATRule{SrcNet: "fd00::/8"})
+			}
+
+			warnings, err := config.CheckNATConfig(cfg)
+			if len(tc.errs) == 0 {
+				require.NoError(t, err)
+			} else {
+				require.ElementsMatch(t, tc.errs, errorCodes(t, err))
+			}
+			require.ElementsMatch(t, tc.warnings, fieldCodes(warnings))
+		})
+	}
+}
+
+func TestValidateNATConfig_NAT64Only(t *testing.T) {
+	cfg := &config.NATConfig{
+		Name:      "nat64-nse",
+		NAT64:     &config.NAT64Config{Pool: []string{"198.51.100.1"}},
+		SnatRules: []config.SNATRule{{SrcNet: "fd00::/8"}},
+	}
+	require.NoError(t, config.ValidateNATConfig(cfg), "只配置NAT64时不要求natIP")
+}
+
+func TestValidateNATConfig_NPTv6(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		rules []config.NPTv6Rule
+		errs  []string
+	}{
+		{
+			name:  "valid",
+			rules: []config.NPTv6Rule{{InsidePrefix: "fd00:1::/48", OutsidePrefix: "2001:db8:1::/48"}},
+		},
+		{
+			name:  "length mismatch",
+			rules: []config.NPTv6Rule{{InsidePrefix: "fd00:1::/48", OutsidePrefix: "2001:db8:1::/56"}},
+			errs:  []string{"nptv6[0] " + config.CodePrefixLengthMismatch},
+		},
+		{
+			name:  "host bits",
+			rules: []config.NPTv6Rule{{InsidePrefix: "fd00:1::1/48", OutsidePrefix: "2001:db8:1::/48"}},
+			errs:  []string{"nptv6[0].insidePrefix " + config.CodeInvalidCIDR},
+		},
+		{
+			name:  "ipv4 prefix",
+			rules: []config.NPTv6Rule{{InsidePrefix: "fd00:1::/48", OutsidePrefix: "198.51.100.0/24"}},
+			errs:  []string{"nptv6[0].outsidePrefix " + config.CodeInvalidCIDR},
+		},
+		{
+			name:  "missing prefixes",
+			rules: []config.NPTv6Rule{{}},
+			errs:  []string{"nptv6[0].insidePrefix " + config.CodeRequired, "nptv6[0].outsidePrefix " + config.CodeRequired},
+		},
+		{
+			name: "overlapping rules",
+			rules: []config.NPTv6Rule{
+				{InsidePrefix: "fd00:1::/48", OutsidePrefix: "2001:db8:1::/48"},
+				{InsidePrefix: "fd00:1:0:1::/64", OutsidePrefix: "2001:db8:1:1::/64"},
+			},
+			errs: []string{"nptv6[1].insidePrefix " + config.CodeOverlap, "nptv6[1].outsidePrefix " + config.CodeOverlap},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := baseNATConfig()
+			cfg.NatIP = ""
+			cfg.NPTv6 = tc.rules
+
+			err := config.ValidateNATConfig(cfg)
+			if len(tc.errs) == 0 {
+				require.NoError(t, err, "只配置NPTv6时不要求natIP")
+				return
+			}
+			require.ElementsMatch(t, tc.errs, errorCodes(t, err))
+		})
+	}
+}
+
+func TestValidateNATConfig_TwiceNAT(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		pool         []string
+		twiceNAT     bool
+		selfTwiceNAT bool
+		errs         []string
+	}{
+		{name: "twiceNAT with pool", pool: []string{"198.51.100.5"}, twiceNAT: true},
+		{name: "selfTwiceNAT with pool", pool: []string{"198.51.100.0/29"}, selfTwiceNAT: true},
+		{
+			name:     "twiceNAT without pool",
+			twiceNAT: true,
+			errs:     []string{"dnatRules[0].twiceNAT " + config.CodeRequired},
+		},
+		{
+			name:         "selfTwiceNAT without pool",
+			selfTwiceNAT: true,
+			errs:         []string{"dnatRules[0].selfTwiceNAT " + config.CodeRequired},
+		},
+		{
+			name:         "mutually exclusive",
+			pool:         []string{"198.51.100.5"},
+			twiceNAT:     true,
+			selfTwiceNAT: true,
+			errs:         []string{"dnatRules[0] " + config.CodeInvalidValue},
+		},
+		{
+			name: "invalid pool entry",
+			pool: []string{"198.51.100.5", "198.51.100"},
+			errs: []string{"twiceNATPool[1] " + config.CodeInvalidAddressRange},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := baseNATConfig()
+			cfg.TwiceNATPool = tc.pool
+			cfg.DnatRules = []config.DNATRule{{
+				ExternalIP: "203.0.113.10", ExternalPort: 80, InternalIP: "10.0.1.100", InternalPort: 8080, Protocol: "tcp",
+				TwiceNAT: tc.twiceNAT, SelfTwiceNAT: tc.selfTwiceNAT,
+			}}
+
+			err := config.ValidateNATConfig(cfg)
+			if len(tc.errs) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.ElementsMatch(t, tc.errs, errorCodes(t, err))
+		})
+	}
+}
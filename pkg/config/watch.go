@@ -0,0 +1,102 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/pkg/errors"
+)
+
+// WatchFile 监听配置文件变化
+//
+// 监听文件所在目录而不是文件本身:Kubernetes更新ConfigMap卷时会原子替换
+// "..data"符号链接,文件本身的inode并不会收到写事件。
+// 目录中任何与该文件或"..data"相关的事件都会产生一次通知,
+// 连续的多个事件可能合并为一次通知。
+//
+// 注意:通过subPath挂载的ConfigMap不会被kubelet更新,此时只能通过SIGHUP触发重新加载。
+//
+// 参数:
+//   - ctx: 上下文,取消时停止监听并关闭通知通道
+//   - configPath: 配置文件路径
+//
+// 返回:
+//   - <-chan struct{}: 文件变化通知通道
+//   - error: 创建监听器失败
+//
+// 示例:
+//
+//	changes, err := config.WatchFile(ctx, cfg.NATConfigPath)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for range changes {
+//	    // 重新加载配置
+//	}
+func WatchFile(ctx context.Context, configPath string) (<-chan struct{}, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create fsnotify watcher")
+	}
+
+	configPath = filepath.Clean(configPath)
+	dir := filepath.Dir(configPath)
+	if err := watcher.Add(dir); err != nil {
+		_ = watcher.Close()
+		return nil, errors.Wrapf(err, "failed to watch directory %s", dir)
+	}
+
+	changes := make(chan struct{}, 1)
+	go func() {
+		defer close(changes)
+		defer func() { _ = watcher.Close() }()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				name := filepath.Clean(event.Name)
+				if name != configPath && filepath.Base(name) != "..data" {
+					continue
+				}
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.FromContext(ctx).WithField("config", "WatchFile").Warnf("fsnotify error: %v", err)
+			}
+		}
+	}()
+
+	return changes, nil
+}
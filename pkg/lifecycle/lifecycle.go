@@ -35,8 +35,8 @@ import (
 
 // NotifyContext 创建带信号处理的上下文
 //
-// 创建一个会在接收到SIGINT、SIGHUP、SIGTERM、SIGQUIT信号时自动取消的上下文。
-// 用于实现应用的优雅退出。
+// 创建一个会在接收到SIGINT、SIGTERM、SIGQUIT信号时自动取消的上下文。
+// 用于实现应用的优雅退出。SIGHUP用于重新加载配置,见NotifyReload。
 //
 // 返回值：
 //   - ctx: 上下文，会在接收到信号时被取消
@@ -51,12 +51,53 @@ func NotifyContext() (context.Context, context.CancelFunc) {
 		context.Background(),
 		os.Interrupt,
 		// More Linux signals here
-		syscall.SIGHUP,
 		syscall.SIGTERM,
 		syscall.SIGQUIT,
 	)
 }
 
+// NotifyReload 将SIGHUP信号转换为配置重新加载通知
+//
+// 每收到一次SIGHUP产生一次通知,处理不及时的连续信号会合并为一次。
+// ctx取消时停止接收信号并关闭通知通道。
+// 应在NotifyContext之后立即调用:调用之前收到的SIGHUP会按Go的默认行为终止进程。
+//
+// 参数：
+//   - ctx: 上下文，控制信号监听的生命周期
+//
+// 返回值：
+//   - 重新加载通知通道
+//
+// 示例：
+//
+//	for range lifecycle.NotifyReload(ctx) {
+//	    // 重新加载配置
+//	}
+func NotifyReload(ctx context.Context) <-chan struct{} {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+
+	reloadCh := make(chan struct{}, 1)
+	go func() {
+		defer close(reloadCh)
+		defer signal.Stop(sigCh)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigCh:
+				select {
+				case reloadCh <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return reloadCh
+}
+
 // InitializeLogging 初始化日志系统
 //
 // 设置日志格式化器、启用追踪、配置日志级别，并设置信号动态切换日志级别的功能。
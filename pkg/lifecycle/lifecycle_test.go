@@ -23,6 +23,7 @@ package lifecycle_test
 import (
 	"context"
 	"errors"
+	"syscall"
 	"testing"
 	"time"
 
@@ -57,6 +58,30 @@ func TestNotifyContext(t *testing.T) {
 	}
 }
 
+func TestNotifyReload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	reloadCh := lifecycle.NotifyReload(ctx)
+
+	// SIGHUP应该产生重新加载通知，而不是终止进程
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+
+	select {
+	case <-reloadCh:
+		// 正确：收到重新加载通知
+	case <-time.After(time.Second):
+		t.Fatal("收到SIGHUP后应该产生重新加载通知")
+	}
+
+	// 取消上下文后通知通道应该被关闭
+	cancel()
+	select {
+	case _, ok := <-reloadCh:
+		require.False(t, ok, "取消上下文后通知通道应该被关闭")
+	case <-time.After(time.Second):
+		t.Fatal("取消上下文后通知通道应该被关闭")
+	}
+}
+
 func TestInitializeLogging_ValidLevel(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -0,0 +1,305 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
+)
+
+// Snapshot 某一时刻的NAT指标
+type Snapshot struct {
+	// SessionsByProtocol 协议 → 活动会话数
+	SessionsByProtocol map[string]int
+
+	// SessionsByInsideIP 内部地址 → 活动会话数
+	SessionsByInsideIP map[string]int
+
+	// PoolPorts 地址池中每个地址范围的端口使用情况
+	//
+	// 按配置中的地址范围聚合,时间序列数量不随地址池大小增长。
+	PoolPorts []PoolPortUsage
+
+	// TranslationErrors 转换失败累计计数
+	TranslationErrors vpp.TranslationErrors
+
+	// StaticMappings DNAT静态映射 → 命中统计
+	StaticMappings map[string]StaticMappingHits
+}
+
+// PoolPortUsage 出口地址或地址范围的端口使用情况
+type PoolPortUsage struct {
+	// Pool 地址池名称
+	Pool string
+
+	// Address 出口地址,或"a.b.c.d-e.f.g.h"格式的地址范围
+	Address string
+
+	// Used 动态会话占用的端口数
+	Used int
+
+	// Total 可分配的端口数(动态SNAT端口范围1024-65535的大小 × 地址数)
+	Total int
+}
+
+// StaticMappingHits 单条DNAT静态映射的命中统计
+type StaticMappingHits struct {
+	// Sessions 由该映射创建的活动会话数
+	Sessions int
+
+	// Packets 这些会话累计的报文数
+	Packets uint64
+}
+
+// Collector NAT指标采集器
+//
+// 按固定间隔读取VPP会话表和错误计数,计算出最新的Snapshot。
+// 导出器(Prometheus、OpenTelemetry)只读取最近一次的Snapshot,不直接访问VPP。
+type Collector struct {
+	natConfigurator *vpp.NATConfigurator
+	natConfig       *config.NATConfigHolder
+
+	mu       sync.RWMutex
+	snapshot *Snapshot
+}
+
+// NewCollector 创建NAT指标采集器
+//
+// 参数:
+//   - natConfigurator: NAT配置器,用于读取VPP会话表和错误计数
+//   - natConfig: 当前生效的NAT配置,用于确定地址池和静态映射
+//
+// 返回:
+//   - *Collector: 指标采集器实例
+//
+// 示例:
+//
+//	collector := metrics.NewCollector(natConfigurator, natConfig)
+//	collector.Run(ctx, cfg.MetricsExportInterval)
+func NewCollector(natConfigurator *vpp.NATConfigurator, natConfig *config.NATConfigHolder) *Collector {
+	return &Collector{
+		natConfigurator: natConfigurator,
+		natConfig:       natConfig,
+		snapshot:        newSnapshot(),
+	}
+}
+
+// Run 立即采集一次,之后按interval周期采集
+//
+// 函数在后台运行,ctx取消时退出。采集失败只记录日志,保留上一次的Snapshot。
+//
+// 参数:
+//   - ctx: 上下文,控制采集生命周期
+//   - interval: 采集间隔
+func (c *Collector) Run(ctx context.Context, interval time.Duration) {
+	logger := log.FromContext(ctx).WithField("metrics", "Collector")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := c.Poll(ctx); err != nil {
+				logger.Warnf("NAT指标采集失败: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Poll 执行一次采集并更新Snapshot
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - error: 读取VPP会话表或错误计数失败
+func (c *Collector) Poll(ctx context.Context) error {
+	sessions, err := c.natConfigurator.DumpSessions(ctx)
+	if err != nil {
+		return err
+	}
+	translationErrors, err := c.natConfigurator.DumpTranslationErrors()
+	if err != nil {
+		return err
+	}
+
+	snapshot := buildSnapshot(c.natConfig.Load(), sessions)
+	snapshot.TranslationErrors = *translationErrors
+
+	c.mu.Lock()
+	c.snapshot = snapshot
+	c.mu.Unlock()
+
+	return nil
+}
+
+// AddressUsage 读取VPP会话表,返回地址池中每个出口地址的端口使用情况
+//
+// 结果按地址展开,只用于按需查询(natctl pools),不更新Snapshot。
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - []PoolPortUsage: 每个出口地址的端口使用情况
+//   - error: 读取VPP会话表失败
+func (c *Collector) AddressUsage(ctx context.Context) ([]PoolPortUsage, error) {
+	sessions, err := c.natConfigurator.DumpSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return poolUsage(c.natConfig.Load(), dynamicPortsUsed(sessions), true), nil
+}
+
+// Snapshot 返回最近一次采集的指标,调用方不得修改返回值
+func (c *Collector) Snapshot() *Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.snapshot
+}
+
+// newSnapshot 创建空的Snapshot
+func newSnapshot() *Snapshot {
+	return &Snapshot{
+		SessionsByProtocol: make(map[string]int),
+		SessionsByInsideIP: make(map[string]int),
+		StaticMappings:     make(map[string]StaticMappingHits),
+	}
+}
+
+// buildSnapshot 由会话表和当前配置计算指标
+func buildSnapshot(natConfig *config.NATConfig, sessions []vpp.Session) *Snapshot {
+	snapshot := newSnapshot()
+
+	// 展开后的外部端点 → 规则名称,地址一对一映射按外部地址匹配
+	mappings := make(map[string]string, len(natConfig.DnatRules))
+	addrMappings := make(map[string]string)
+	for _, rule := range natConfig.DnatRules {
+		name := StaticMappingName(rule)
+		snapshot.StaticMappings[name] = StaticMappingHits{}
+		if rule.IsAddressOnly() {
+			addrMappings[rule.ExternalIP] = name
+			continue
+		}
+		for _, mapping := range rule.Expand() {
+			mappings[externalKey(mapping.Protocol, mapping.ExternalIP, mapping.ExternalPort)] = name
+		}
+	}
+
+	for i := range sessions {
+		s := &sessions[i]
+		snapshot.SessionsByProtocol[s.Protocol]++
+		snapshot.SessionsByInsideIP[s.InsideIP]++
+
+		if !s.Static {
+			continue
+		}
+		name, ok := mappings[externalKey(s.Protocol, s.OutsideIP, s.OutsidePort)]
+		if !ok {
+			name, ok = addrMappings[s.OutsideIP]
+		}
+		if ok {
+			hits := snapshot.StaticMappings[name]
+			hits.Sessions++
+			hits.Packets += uint64(s.TotalPkts)
+			snapshot.StaticMappings[name] = hits
+		}
+	}
+
+	snapshot.PoolPorts = poolUsage(natConfig, dynamicPortsUsed(sessions), false)
+
+	return snapshot
+}
+
+// dynamicPortsUsed 返回每个出口地址被动态会话占用的端口数
+func dynamicPortsUsed(sessions []vpp.Session) map[string]int {
+	portsUsed := make(map[string]int)
+	for i := range sessions {
+		if !sessions[i].Static {
+			portsUsed[sessions[i].OutsideIP]++
+		}
+	}
+	return portsUsed
+}
+
+// poolUsage 返回各地址池的端口使用情况
+//
+// perAddress为false时每个地址范围一条,否则每个出口地址一条。
+func poolUsage(natConfig *config.NATConfig, portsUsed map[string]int, perAddress bool) []PoolPortUsage {
+	var usages []PoolPortUsage
+	// nat44-ed始终从1024-65535分配动态SNAT端口,与配置的portRange无关
+	total := config.DefaultPortRange().AvailablePortsCount()
+	addPool := func(pool string, ranges []config.AddressRange) {
+		for _, r := range ranges {
+			if perAddress {
+				for _, ip := range r.Addresses() {
+					usages = append(usages, PoolPortUsage{Pool: pool, Address: ip.String(), Used: portsUsed[ip.String()], Total: total})
+				}
+				continue
+			}
+			// 按会话占用的地址汇总,避免每次采集展开整个范围
+			usage := PoolPortUsage{Pool: pool, Address: r.String(), Total: total * r.Size()}
+			for addr, used := range portsUsed {
+				if r.Contains(net.ParseIP(addr)) {
+					usage.Used += used
+				}
+			}
+			usages = append(usages, usage)
+		}
+	}
+	if ranges, err := natConfig.PoolRanges(); err == nil {
+		addPool(config.DefaultPoolName, ranges)
+	}
+	for i := range natConfig.Pools {
+		if ranges, err := natConfig.Pools[i].Ranges(); err == nil {
+			addPool(natConfig.Pools[i].Name, ranges)
+		}
+	}
+	return usages
+}
+
+// StaticMappingName 返回DNAT静态映射在指标中的名称,如"tcp 203.0.113.10:80->10.0.1.100:8080"
+//
+// 端口范围和地址一对一映射整条规则一个名称,如"both 203.0.113.10:5000-5009->10.0.1.100:6000-6009"。
+func StaticMappingName(rule config.DNATRule) string {
+	return rule.String()
+}
+
+// externalKey 返回静态映射外部端点的唯一键
+func externalKey(protocol, ip string, port uint16) string {
+	return fmt.Sprintf("%s %s:%d", strings.ToLower(protocol), ip, port)
+}
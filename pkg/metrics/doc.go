@@ -0,0 +1,17 @@
+// Package metrics 提供NAT运行指标
+//
+// 本包周期性读取VPP nat44-ed的会话表和错误计数，
+// 汇总为NAT相关指标，并通过Prometheus和OpenTelemetry导出。
+//
+// 主要功能：
+//   - 按协议、内部地址统计活动会话
+//   - 统计地址池中每个出口地址的端口使用率
+//   - 统计转换失败(端口耗尽、超过最大会话数)
+//   - 统计DNAT静态映射命中的会话
+//
+// 使用示例：
+//
+//	collector := metrics.NewCollector(natConfigurator, natConfig)
+//	collector.Run(ctx, cfg.MetricsExportInterval)
+//	go metrics.ListenAndServe(ctx, cfg.PrometheusListenOn, collector)
+package metrics
@@ -0,0 +1,122 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// meterName OpenTelemetry meter名称
+const meterName = "github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/metrics"
+
+// RegisterOTEL 通过全局OpenTelemetry MeterProvider导出NAT指标
+//
+// 注册与Prometheus相同的指标(名称以"."分隔),导出时读取最近一次的Snapshot。
+// 应在opentelemetry.Init之后调用,否则指标会注册到空实现的MeterProvider上。
+//
+// 参数:
+//   - collector: NAT指标采集器
+//
+// 返回:
+//   - error: 指标注册失败
+//
+// 示例:
+//
+//	if opentelemetry.IsEnabled() {
+//	    if err := metrics.RegisterOTEL(collector); err != nil {
+//	        log.Errorf("注册NAT指标失败: %v", err)
+//	    }
+//	}
+func RegisterOTEL(collector *Collector) error {
+	meter := otel.Meter(meterName)
+
+	sessions, err := meter.Int64ObservableGauge("nse_nat.sessions",
+		metric.WithDescription("Active NAT sessions per protocol."))
+	if err != nil {
+		return errors.Wrap(err, "failed to create nse_nat.sessions gauge")
+	}
+	userSessions, err := meter.Int64ObservableGauge("nse_nat.user_sessions",
+		metric.WithDescription("Active NAT sessions per inside address."))
+	if err != nil {
+		return errors.Wrap(err, "failed to create nse_nat.user_sessions gauge")
+	}
+	poolPortsUsed, err := meter.Int64ObservableGauge("nse_nat.pool_ports_used",
+		metric.WithDescription("Ports in use by dynamic sessions per pool address range."))
+	if err != nil {
+		return errors.Wrap(err, "failed to create nse_nat.pool_ports_used gauge")
+	}
+	poolPortsTotal, err := meter.Int64ObservableGauge("nse_nat.pool_ports_total",
+		metric.WithDescription("Ports available for allocation per pool address range."))
+	if err != nil {
+		return errors.Wrap(err, "failed to create nse_nat.pool_ports_total gauge")
+	}
+	translationFailures, err := meter.Int64ObservableCounter("nse_nat.translation_failures",
+		metric.WithDescription("Failed translations since VPP start."))
+	if err != nil {
+		return errors.Wrap(err, "failed to create nse_nat.translation_failures counter")
+	}
+	staticMappingSessions, err := meter.Int64ObservableGauge("nse_nat.static_mapping_sessions",
+		metric.WithDescription("Active sessions created by a DNAT static mapping."))
+	if err != nil {
+		return errors.Wrap(err, "failed to create nse_nat.static_mapping_sessions gauge")
+	}
+	staticMappingPackets, err := meter.Int64ObservableGauge("nse_nat.static_mapping_packets",
+		metric.WithDescription("Packets of active sessions created by a DNAT static mapping."))
+	if err != nil {
+		return errors.Wrap(err, "failed to create nse_nat.static_mapping_packets gauge")
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s := collector.Snapshot()
+
+		for protocol, n := range s.SessionsByProtocol {
+			o.ObserveInt64(sessions, int64(n), metric.WithAttributes(attribute.String("protocol", protocol)))
+		}
+		for insideIP, n := range s.SessionsByInsideIP {
+			o.ObserveInt64(userSessions, int64(n), metric.WithAttributes(attribute.String("inside_address", insideIP)))
+		}
+		for _, usage := range s.PoolPorts {
+			attrs := metric.WithAttributes(attribute.String("pool", usage.Pool), attribute.String("range", usage.Address))
+			o.ObserveInt64(poolPortsUsed, int64(usage.Used), attrs)
+			o.ObserveInt64(poolPortsTotal, int64(usage.Total), attrs)
+		}
+		o.ObserveInt64(translationFailures, int64(s.TranslationErrors.OutOfPorts),
+			metric.WithAttributes(attribute.String("reason", reasonOutOfPorts)))
+		o.ObserveInt64(translationFailures, int64(s.TranslationErrors.MaxSessions),
+			metric.WithAttributes(attribute.String("reason", reasonMaxSessions)))
+		for name, hits := range s.StaticMappings {
+			attrs := metric.WithAttributes(attribute.String("mapping", name))
+			o.ObserveInt64(staticMappingSessions, int64(hits.Sessions), attrs)
+			o.ObserveInt64(staticMappingPackets, int64(hits.Packets), attrs)
+		}
+		return nil
+	}, sessions, userSessions, poolPortsUsed, poolPortsTotal, translationFailures, staticMappingSessions, staticMappingPackets)
+	if err != nil {
+		return errors.Wrap(err, "failed to register NAT metrics callback")
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// 指标名称前缀
+const namespace = "nse_nat"
+
+var (
+	sessionsDesc = prometheus.NewDesc(namespace+"_sessions",
+		"Active NAT sessions per protocol.", []string{"protocol"}, nil)
+	userSessionsDesc = prometheus.NewDesc(namespace+"_user_sessions",
+		"Active NAT sessions per inside address.", []string{"inside_address"}, nil)
+	poolPortsUsedDesc = prometheus.NewDesc(namespace+"_pool_ports_used",
+		"Ports in use by dynamic sessions per pool address range.", []string{"pool", "range"}, nil)
+	poolPortsTotalDesc = prometheus.NewDesc(namespace+"_pool_ports_total",
+		"Ports available for allocation per pool address range.", []string{"pool", "range"}, nil)
+	translationFailuresDesc = prometheus.NewDesc(namespace+"_translation_failures_total",
+		"Failed translations since VPP start.", []string{"reason"}, nil)
+	staticMappingSessionsDesc = prometheus.NewDesc(namespace+"_static_mapping_sessions",
+		"Active sessions created by a DNAT static mapping.", []string{"mapping"}, nil)
+	staticMappingPacketsDesc = prometheus.NewDesc(namespace+"_static_mapping_packets",
+		"Packets of active sessions created by a DNAT static mapping.", []string{"mapping"}, nil)
+)
+
+// 转换失败原因标签值
+const (
+	reasonOutOfPorts  = "out_of_ports"
+	reasonMaxSessions = "max_sessions"
+)
+
+// Describe 实现prometheus.Collector接口
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sessionsDesc
+	ch <- userSessionsDesc
+	ch <- poolPortsUsedDesc
+	ch <- poolPortsTotalDesc
+	ch <- translationFailuresDesc
+	ch <- staticMappingSessionsDesc
+	ch <- staticMappingPacketsDesc
+}
+
+// Collect 实现prometheus.Collector接口,导出最近一次的Snapshot
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.Snapshot()
+
+	for protocol, n := range s.SessionsByProtocol {
+		ch <- prometheus.MustNewConstMetric(sessionsDesc, prometheus.GaugeValue, float64(n), protocol)
+	}
+	for insideIP, n := range s.SessionsByInsideIP {
+		ch <- prometheus.MustNewConstMetric(userSessionsDesc, prometheus.GaugeValue, float64(n), insideIP)
+	}
+	for _, usage := range s.PoolPorts {
+		ch <- prometheus.MustNewConstMetric(poolPortsUsedDesc, prometheus.GaugeValue, float64(usage.Used), usage.Pool, usage.Address)
+		ch <- prometheus.MustNewConstMetric(poolPortsTotalDesc, prometheus.GaugeValue, float64(usage.Total), usage.Pool, usage.Address)
+	}
+	ch <- prometheus.MustNewConstMetric(translationFailuresDesc, prometheus.CounterValue,
+		float64(s.TranslationErrors.OutOfPorts), reasonOutOfPorts)
+	ch <- prometheus.MustNewConstMetric(translationFailuresDesc, prometheus.CounterValue,
+		float64(s.TranslationErrors.MaxSessions), reasonMaxSessions)
+	for name, hits := range s.StaticMappings {
+		ch <- prometheus.MustNewConstMetric(staticMappingSessionsDesc, prometheus.GaugeValue, float64(hits.Sessions), name)
+		ch <- prometheus.MustNewConstMetric(staticMappingPacketsDesc, prometheus.GaugeValue, float64(hits.Packets), name)
+	}
+}
+
+// ListenAndServe 在listenOn上提供Prometheus /metrics接口
+//
+// 阻塞直到ctx取消或监听失败,监听失败只记录日志。
+//
+// 参数:
+//   - ctx: 上下文,取消时关闭HTTP服务器
+//   - listenOn: 监听地址(如"localhost:9090";需要被Pod外的Prometheus抓取时使用":9090")
+//   - collector: NAT指标采集器
+//
+// 示例:
+//
+//	go metrics.ListenAndServe(ctx, cfg.PrometheusListenOn, collector)
+func ListenAndServe(ctx context.Context, listenOn string, collector *Collector) {
+	logger := log.FromContext(ctx).WithField("metrics", "ListenAndServe")
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	server := &http.Server{
+		Addr:              listenOn,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Infof("Prometheus指标接口监听 %s/metrics", listenOn)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Errorf("Prometheus指标接口退出: %v", err)
+	}
+}
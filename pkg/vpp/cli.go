@@ -0,0 +1,58 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/networkservicemesh/govpp/binapi/vlib"
+	"github.com/pkg/errors"
+)
+
+// errUnknownCLICommand VPP无法识别CLI命令(如对应插件未加载或命令已移除)
+var errUnknownCLICommand = errors.New("unknown VPP CLI command")
+
+// runCLI 通过CliInband执行VPP CLI命令并返回输出
+//
+// 用于尚无对应二进制API的配置项。VPP对无法识别的命令同样返回Retval 0,
+// 因此还需检查输出中的错误提示;无法识别的命令返回包装errUnknownCLICommand的错误。
+func (nc *NATConfigurator) runCLI(cmd string) (string, error) {
+	req := &vlib.CliInband{Cmd: cmd}
+	reply := &vlib.CliInbandReply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return "", errors.Wrapf(err, "VPP API CliInband failed for command '%s'", cmd)
+	}
+
+	if reply.Retval != 0 {
+		return "", fmt.Errorf("VPP returned error code %d when executing CLI command '%s'", reply.Retval, cmd)
+	}
+
+	if strings.Contains(reply.Reply, "unknown input") {
+		return "", errors.Wrapf(errUnknownCLICommand, "'%s': %s", cmd, strings.TrimSpace(reply.Reply))
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(reply.Reply), "error") {
+		return "", fmt.Errorf("VPP CLI command '%s' failed: %s", cmd, strings.TrimSpace(reply.Reply))
+	}
+
+	return reply.Reply, nil
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/networkservicemesh/govpp/binapi/nat_types"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// dnatTag VPP静态映射标签,用于识别由本NSE下发的DNAT映射
+const dnatTag = "nse-nat-dnat"
+
+// IP协议号
+const (
+	protoICMP uint8 = 1
+	protoTCP  uint8 = 6
+	protoUDP  uint8 = 17
+)
+
+// DNATRuleError 单条DNAT规则的配置错误
+//
+// 记录出错规则在配置中的下标和规则内容,便于定位问题规则。
+type DNATRuleError struct {
+	// Index 规则在dnatRules中的下标
+	Index int
+
+	// Rule 出错的DNAT规则
+	Rule config.DNATRule
+
+	// Err VPP返回的错误
+	Err error
+}
+
+// Error 实现error接口
+func (e *DNATRuleError) Error() string {
+	return fmt.Sprintf("dnatRules[%d] (%s): %v", e.Index, e.Rule, e.Err)
+}
+
+// Unwrap 返回底层错误
+func (e *DNATRuleError) Unwrap() error {
+	return e.Err
+}
+
+// AddStaticMapping 添加DNAT静态映射
+//
+// 使用Nat44AddDelStaticMappingV2将 externalIP:externalPort 映射到 internalIP:internalPort,
+// 实现入站端口转发。规则启用TwiceNAT/SelfTwiceNAT时设置对应的标志,
+// 源地址从twice-NAT地址池(AddTwiceNATAddressRange)中选取。
+//
+// VPP的静态映射只支持单个协议和端口,端口范围和"both"协议按config.DNATRule.Expand
+// 展开为多条映射逐条下发,中途失败时删除已下发的部分;地址一对一映射设置NAT_IS_ADDR_ONLY,
+// 转换全部协议。
+//
+// 参数:
+//   - rule: DNAT规则
+//
+// 返回:
+//   - error: 规则解析错误、VPP API调用错误或VPP返回的错误码
+//
+// 示例:
+//
+//	err := natCfg.AddStaticMapping(config.DNATRule{
+//	    ExternalIP: "203.0.113.10", ExternalPort: 80,
+//	    InternalIP: "10.0.1.100", InternalPort: 8080,
+//	    Protocol: "tcp",
+//	})
+func (nc *NATConfigurator) AddStaticMapping(rule config.DNATRule) error {
+	mappings := rule.Expand()
+	for i, mapping := range mappings {
+		if err := nc.addDelStaticMapping(mapping, true); err != nil {
+			for _, added := range mappings[:i] {
+				_ = nc.addDelStaticMapping(added, false)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// DelStaticMapping 删除DNAT静态映射
+//
+// 展开后的映射逐条删除,单条失败不影响其余映射。
+//
+// 参数:
+//   - rule: 与添加时相同的DNAT规则
+//
+// 返回:
+//   - error: 第一个失败映射的规则解析错误、VPP API调用错误或VPP返回的错误码
+func (nc *NATConfigurator) DelStaticMapping(rule config.DNATRule) error {
+	var firstErr error
+	for _, mapping := range rule.Expand() {
+		if err := nc.addDelStaticMapping(mapping, false); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// AddDNATRules 批量添加DNAT静态映射
+//
+// 逐条下发规则,单条失败不影响其余规则。
+//
+// 参数:
+//   - rules: DNAT规则列表
+//
+// 返回:
+//   - []*DNATRuleError: 每条失败规则对应一个错误,全部成功时为空
+//
+// 示例:
+//
+//	for _, err := range natCfg.AddDNATRules(natConfig.DnatRules) {
+//	    log.Errorf("DNAT规则配置失败: %v", err)
+//	}
+func (nc *NATConfigurator) AddDNATRules(rules []config.DNATRule) []*DNATRuleError {
+	var ruleErrs []*DNATRuleError
+	for i, rule := range rules {
+		if err := nc.AddStaticMapping(rule); err != nil {
+			ruleErrs = append(ruleErrs, &DNATRuleError{Index: i, Rule: rule, Err: err})
+		}
+	}
+	return ruleErrs
+}
+
+// DelDNATRules 批量删除DNAT静态映射
+//
+// 参数:
+//   - rules: DNAT规则列表
+//
+// 返回:
+//   - []*DNATRuleError: 每条失败规则对应一个错误,全部成功时为空
+func (nc *NATConfigurator) DelDNATRules(rules []config.DNATRule) []*DNATRuleError {
+	var ruleErrs []*DNATRuleError
+	for i, rule := range rules {
+		if err := nc.DelStaticMapping(rule); err != nil {
+			ruleErrs = append(ruleErrs, &DNATRuleError{Index: i, Rule: rule, Err: err})
+		}
+	}
+	return ruleErrs
+}
+
+// addDelStaticMapping 添加或删除一条展开后的静态映射
+func (nc *NATConfigurator) addDelStaticMapping(rule config.DNATRule, isAdd bool) error {
+	externalIP, err := parseIPv4(rule.ExternalIP)
+	if err != nil {
+		return errors.Wrap(err, "invalid externalIP")
+	}
+
+	internalIP, err := parseIPv4(rule.InternalIP)
+	if err != nil {
+		return errors.Wrap(err, "invalid internalIP")
+	}
+
+	// 地址一对一映射不区分协议和端口
+	var proto uint8
+	if !rule.IsAddressOnly() {
+		if proto, err = protocolNumber(rule.Protocol); err != nil {
+			return err
+		}
+	}
+
+	req := &nat44_ed.Nat44AddDelStaticMappingV2{
+		IsAdd:             isAdd,
+		Flags:             staticMappingFlags(rule),
+		LocalIPAddress:    internalIP,
+		ExternalIPAddress: externalIP,
+		Protocol:          proto,
+		LocalPort:         rule.InternalPort,
+		ExternalPort:      rule.ExternalPort,
+		ExternalSwIfIndex: ^interface_types.InterfaceIndex(0), // ~0表示使用ExternalIPAddress而非接口地址
+		VrfID:             0,
+		Tag:               dnatTag,
+	}
+
+	reply := &nat44_ed.Nat44AddDelStaticMappingV2Reply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return errors.Wrapf(err, "VPP API Nat44AddDelStaticMappingV2 failed for %s", rule)
+	}
+
+	if reply.Retval != 0 {
+		return fmt.Errorf("VPP returned error code %d when %s static mapping %s", reply.Retval, addDelVerb(isAdd), rule)
+	}
+
+	return nil
+}
+
+// staticMappingFlags 返回DNAT规则对应的静态映射标志
+func staticMappingFlags(rule config.DNATRule) nat_types.NatConfigFlags {
+	var flags nat_types.NatConfigFlags
+	if rule.IsAddressOnly() {
+		flags |= nat_types.NAT_IS_ADDR_ONLY
+	}
+	if rule.TwiceNAT {
+		flags |= nat_types.NAT_IS_TWICE_NAT
+	}
+	if rule.SelfTwiceNAT {
+		flags |= nat_types.NAT_IS_SELF_TWICE_NAT
+	}
+	return flags
+}
+
+// protocolNumber 将协议名转换为IP协议号
+func protocolNumber(protocol string) (uint8, error) {
+	switch strings.ToLower(protocol) {
+	case "tcp":
+		return protoTCP, nil
+	case "udp":
+		return protoUDP, nil
+	case "icmp":
+		return protoICMP, nil
+	default:
+		return 0, fmt.Errorf("unsupported protocol: '%s'", protocol)
+	}
+}
+
+// protocolName 将IP协议号转换为协议名
+func protocolName(proto uint8) string {
+	switch proto {
+	case protoTCP:
+		return "tcp"
+	case protoUDP:
+		return "udp"
+	case protoICMP:
+		return "icmp"
+	default:
+		return strconv.Itoa(int(proto))
+	}
+}
+
+// addDelVerb 返回用于错误信息的操作描述
+func addDelVerb(isAdd bool) string {
+	if isAdd {
+		return "adding"
+	}
+	return "deleting"
+}
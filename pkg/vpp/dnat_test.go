@@ -0,0 +1,134 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/networkservicemesh/govpp/binapi/nat_types"
+	"github.com/stretchr/testify/require"
+	"go.fd.io/govpp/api"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
+)
+
+func TestAddStaticMapping_PortRangeBothProtocols(t *testing.T) {
+	conn := &fakeConn{}
+	natCfg := vpp.NewNATConfigurator(conn)
+
+	require.NoError(t, natCfg.AddStaticMapping(config.DNATRule{
+		ExternalIP: "203.0.113.10", ExternalPort: 5000, ExternalPortEnd: 5002,
+		InternalIP: "10.0.1.100", InternalPort: 6000, InternalPortEnd: 6002,
+		Protocol: "both",
+	}))
+
+	mappings := recorded[*nat44_ed.Nat44AddDelStaticMappingV2](conn)
+	require.Len(t, mappings, 6, "3个端口 × tcp和udp")
+	for i, m := range mappings {
+		require.True(t, m.IsAdd)
+		require.Equal(t, uint16(5000+i%3), m.ExternalPort)
+		require.Equal(t, uint16(6000+i%3), m.LocalPort, "内部端口应与外部端口一一对应")
+		require.Zero(t, m.Flags&nat_types.NAT_IS_ADDR_ONLY)
+	}
+	require.Equal(t, uint8(6), mappings[0].Protocol)
+	require.Equal(t, uint8(17), mappings[3].Protocol)
+}
+
+func TestAddStaticMapping_AddressOnly(t *testing.T) {
+	conn := &fakeConn{}
+	natCfg := vpp.NewNATConfigurator(conn)
+	rule := config.DNATRule{ExternalIP: "203.0.113.11", InternalIP: "10.0.1.101"}
+
+	require.NoError(t, natCfg.AddStaticMapping(rule))
+	require.NoError(t, natCfg.DelStaticMapping(rule))
+
+	mappings := recorded[*nat44_ed.Nat44AddDelStaticMappingV2](conn)
+	require.Len(t, mappings, 2)
+	for _, m := range mappings {
+		require.Equal(t, nat_types.NAT_IS_ADDR_ONLY, m.Flags)
+		require.Zero(t, m.Protocol)
+		require.Zero(t, m.ExternalPort)
+		require.Zero(t, m.LocalPort)
+		require.Equal(t, "203.0.113.11", m.ExternalIPAddress.String())
+		require.Equal(t, "10.0.1.101", m.LocalIPAddress.String())
+	}
+	require.True(t, mappings[0].IsAdd)
+	require.False(t, mappings[1].IsAdd)
+}
+
+func TestAddStaticMapping_PortRangeRollsBack(t *testing.T) {
+	conn := &fakeConn{fail: func(req api.Message) error {
+		if r, ok := req.(*nat44_ed.Nat44AddDelStaticMappingV2); ok && r.IsAdd && r.ExternalPort == 5002 {
+			return errors.New("port in use")
+		}
+		return nil
+	}}
+	natCfg := vpp.NewNATConfigurator(conn)
+
+	require.Error(t, natCfg.AddStaticMapping(config.DNATRule{
+		ExternalIP: "203.0.113.10", ExternalPort: 5000, ExternalPortEnd: 5003,
+		InternalIP: "10.0.1.100", InternalPort: 5000, InternalPortEnd: 5003,
+		Protocol: "tcp",
+	}))
+
+	var removed []uint16
+	for _, m := range recorded[*nat44_ed.Nat44AddDelStaticMappingV2](conn) {
+		if !m.IsAdd {
+			removed = append(removed, m.ExternalPort)
+		}
+	}
+	require.Equal(t, []uint16{5000, 5001}, removed, "失败时应删除已下发的端口")
+}
+
+func TestAddDNATRules_ReportsEachFailedRule(t *testing.T) {
+	errPortInUse := errors.New("port in use")
+	conn := &fakeConn{fail: func(req api.Message) error {
+		if r, ok := req.(*nat44_ed.Nat44AddDelStaticMappingV2); ok && r.ExternalPort == 81 {
+			return errPortInUse
+		}
+		return nil
+	}}
+	natCfg := vpp.NewNATConfigurator(conn)
+	rules := []config.DNATRule{
+		{ExternalIP: "203.0.113.10", ExternalPort: 80, InternalIP: "10.0.1.100", InternalPort: 8080, Protocol: "tcp"},
+		{ExternalIP: "203.0.113.10", ExternalPort: 81, InternalIP: "10.0.1.100", InternalPort: 8081, Protocol: "tcp"},
+		{ExternalIP: "203.0.113.10", ExternalPort: 82, InternalIP: "not-an-ip", InternalPort: 8082, Protocol: "tcp"},
+		{ExternalIP: "203.0.113.10", ExternalPort: 83, InternalIP: "10.0.1.100", InternalPort: 8083, Protocol: "udp"},
+	}
+
+	ruleErrs := natCfg.AddDNATRules(rules)
+	require.Len(t, ruleErrs, 2, "单条规则失败不应影响其余规则")
+	require.Equal(t, 1, ruleErrs[0].Index)
+	require.ErrorIs(t, ruleErrs[0], errPortInUse)
+	require.Equal(t, 2, ruleErrs[1].Index)
+	require.Equal(t, rules[2], ruleErrs[1].Rule)
+
+	var added []uint16
+	for _, m := range recorded[*nat44_ed.Nat44AddDelStaticMappingV2](conn) {
+		if m.IsAdd {
+			added = append(added, m.ExternalPort)
+		}
+	}
+	require.Equal(t, []uint16{80, 81, 83}, added)
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"context"
+	"io"
+	"net"
+
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/networkservicemesh/govpp/binapi/nat_types"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// NATState nat44-ed配置状态
+//
+// 既用于描述从VPP读取的当前状态,也用于描述由NATConfig和活动连接构建的期望状态。
+type NATState struct {
+	// Addresses SNAT地址池中的地址范围
+	Addresses []config.AddressRange
+
+	// TwiceNATAddresses twice-NAT地址池中的地址范围
+	TwiceNATAddresses []config.AddressRange
+
+	// Interfaces 启用NAT特性的接口: 接口索引 → NAT_IS_INSIDE/NAT_IS_OUTSIDE标志
+	Interfaces map[uint32]nat_types.NatConfigFlags
+
+	// StaticMappings 由本NSE管理的DNAT静态映射,每条只有一个协议和端口(或为地址一对一映射)
+	StaticMappings []config.DNATRule
+
+	// LBMappings 由本NSE管理的负载均衡DNAT静态映射
+	LBMappings []config.LBRule
+
+	// Exemptions 由本NSE管理的NAT豁免(身份映射)
+	Exemptions []config.Exemption
+}
+
+// DumpState 读取VPP当前的nat44-ed配置
+//
+// 依次调用Nat44AddressDump、Nat44InterfaceDump、Nat44StaticMappingDump、
+// Nat44LbStaticMappingDump和Nat44IdentityMappingDump。
+// 各类映射只返回带本NSE标签(dnatTag、exemptionTag)的条目,其他来源的映射不受管理。
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - *NATState: VPP当前状态(地址已合并为连续范围)
+//   - error: VPP API调用错误
+func (nc *NATConfigurator) DumpState(ctx context.Context) (*NATState, error) {
+	client := nat44_ed.NewServiceClient(nc.vppConn)
+	state := &NATState{Interfaces: make(map[uint32]nat_types.NatConfigFlags)}
+
+	addrStream, err := client.Nat44AddressDump(ctx, &nat44_ed.Nat44AddressDump{})
+	if err != nil {
+		return nil, errors.Wrap(err, "VPP API Nat44AddressDump failed")
+	}
+	for {
+		details, err := addrStream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "VPP API Nat44AddressDump failed")
+		}
+		ip := net.IP(details.IPAddress[:])
+		r := config.AddressRange{First: ip, Last: ip, VrfID: details.VrfID}
+		if details.Flags&nat_types.NAT_IS_TWICE_NAT != 0 {
+			state.TwiceNATAddresses = append(state.TwiceNATAddresses, r)
+			continue
+		}
+		state.Addresses = append(state.Addresses, r)
+	}
+	state.Addresses = config.MergeRanges(state.Addresses)
+	state.TwiceNATAddresses = config.MergeRanges(state.TwiceNATAddresses)
+
+	ifStream, err := client.Nat44InterfaceDump(ctx, &nat44_ed.Nat44InterfaceDump{})
+	if err != nil {
+		return nil, errors.Wrap(err, "VPP API Nat44InterfaceDump failed")
+	}
+	for {
+		details, err := ifStream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "VPP API Nat44InterfaceDump failed")
+		}
+		state.Interfaces[uint32(details.SwIfIndex)] |= details.Flags
+	}
+
+	smStream, err := client.Nat44StaticMappingDump(ctx, &nat44_ed.Nat44StaticMappingDump{})
+	if err != nil {
+		return nil, errors.Wrap(err, "VPP API Nat44StaticMappingDump failed")
+	}
+	for {
+		details, err := smStream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "VPP API Nat44StaticMappingDump failed")
+		}
+		if details.Tag != dnatTag {
+			continue
+		}
+		rule := config.DNATRule{
+			ExternalIP:   details.ExternalIPAddress.String(),
+			InternalIP:   details.LocalIPAddress.String(),
+			TwiceNAT:     details.Flags&nat_types.NAT_IS_TWICE_NAT != 0,
+			SelfTwiceNAT: details.Flags&nat_types.NAT_IS_SELF_TWICE_NAT != 0,
+		}
+		if details.Flags&nat_types.NAT_IS_ADDR_ONLY == 0 {
+			rule.ExternalPort = details.ExternalPort
+			rule.InternalPort = details.LocalPort
+			rule.Protocol = protocolName(details.Protocol)
+		}
+		state.StaticMappings = append(state.StaticMappings, rule)
+	}
+
+	lbStream, err := client.Nat44LbStaticMappingDump(ctx, &nat44_ed.Nat44LbStaticMappingDump{})
+	if err != nil {
+		return nil, errors.Wrap(err, "VPP API Nat44LbStaticMappingDump failed")
+	}
+	for {
+		details, err := lbStream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "VPP API Nat44LbStaticMappingDump failed")
+		}
+		if details.Tag != dnatTag {
+			continue
+		}
+		rule := config.LBRule{
+			ExternalIP:   details.ExternalAddr.String(),
+			ExternalPort: details.ExternalPort,
+			Protocol:     protocolName(details.Protocol),
+		}
+		for _, local := range details.Locals {
+			rule.Backends = append(rule.Backends, config.LBBackend{
+				IP:     local.Addr.String(),
+				Port:   local.Port,
+				Weight: local.Probability,
+			})
+		}
+		state.LBMappings = append(state.LBMappings, rule)
+	}
+
+	idStream, err := client.Nat44IdentityMappingDump(ctx, &nat44_ed.Nat44IdentityMappingDump{})
+	if err != nil {
+		return nil, errors.Wrap(err, "VPP API Nat44IdentityMappingDump failed")
+	}
+	for {
+		details, err := idStream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "VPP API Nat44IdentityMappingDump failed")
+		}
+		if details.Tag != exemptionTag {
+			continue
+		}
+		e := config.Exemption{IP: details.IPAddress.String()}
+		if details.Flags&nat_types.NAT_IS_ADDR_ONLY == 0 {
+			e.Port = details.Port
+			e.Protocol = protocolName(details.Protocol)
+		}
+		state.Exemptions = append(state.Exemptions, e)
+	}
+
+	return state, nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ConfigureHairpinInterface 配置启用回环(hairpinning)的NAT inside接口
+//
+// 在inside特性之外,同一接口再添加NAT outside特性。inside客户端访问DNAT规则的
+// externalIP:externalPort时,报文在in2out方向同时转换源地址(SNAT地址池)和目的地址
+// (静态映射的内部地址),转发给另一个inside接口后的服务;服务的响应以SNAT地址为目的地址
+// 进入inside接口,需由outside特性做out2in反向转换后回到客户端。
+// outside特性添加失败时移除已添加的inside特性。
+//
+// 参数:
+//   - swIfIndex: VPP接口索引(Server侧memif)
+//
+// 返回:
+//   - error: VPP API调用错误或VPP返回的错误码
+//
+// 示例:
+//
+//	if natConfig.Hairpinning {
+//	    err = natCfg.ConfigureHairpinInterface(serverSideIfIndex)
+//	}
+func (nc *NATConfigurator) ConfigureHairpinInterface(swIfIndex uint32) error {
+	if err := nc.ConfigureInsideInterface(swIfIndex); err != nil {
+		return err
+	}
+
+	if err := nc.ConfigureOutsideInterface(swIfIndex); err != nil {
+		if delErr := nc.RemoveInsideInterface(swIfIndex); delErr != nil {
+			return errors.Wrapf(err, "failed to roll back inside interface %d: %s", swIfIndex, delErr.Error())
+		}
+		return err
+	}
+
+	return nil
+}
+
+// RemoveHairpinInterface 移除接口上的NAT outside和inside特性
+//
+// ConfigureHairpinInterface的逆操作,在连接关闭时调用。
+// outside特性移除失败时仍会尝试移除inside特性,返回第一个错误。
+//
+// 参数:
+//   - swIfIndex: VPP接口索引
+//
+// 返回:
+//   - error: VPP API调用错误或VPP返回的错误码
+func (nc *NATConfigurator) RemoveHairpinInterface(swIfIndex uint32) error {
+	outsideErr := nc.RemoveOutsideInterface(swIfIndex)
+	insideErr := nc.RemoveInsideInterface(swIfIndex)
+	if outsideErr != nil {
+		return outsideErr
+	}
+	return insideErr
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/networkservicemesh/govpp/binapi/nat_types"
+	"github.com/stretchr/testify/require"
+	"go.fd.io/govpp/api"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
+)
+
+// feature 构造一次接口NAT特性请求
+func feature(isAdd bool, swIfIndex uint32, flags nat_types.NatConfigFlags) *nat44_ed.Nat44InterfaceAddDelFeature {
+	return &nat44_ed.Nat44InterfaceAddDelFeature{IsAdd: isAdd, SwIfIndex: interface_types.InterfaceIndex(swIfIndex), Flags: flags}
+}
+
+func TestConfigureHairpinInterface(t *testing.T) {
+	conn := &fakeConn{}
+	natCfg := vpp.NewNATConfigurator(conn)
+
+	require.NoError(t, natCfg.ConfigureHairpinInterface(3))
+	require.Equal(t, []*nat44_ed.Nat44InterfaceAddDelFeature{
+		feature(true, 3, nat_types.NAT_IS_INSIDE),
+		feature(true, 3, nat_types.NAT_IS_OUTSIDE),
+	}, recorded[*nat44_ed.Nat44InterfaceAddDelFeature](conn), "inside接口应同时添加outside特性")
+
+	require.NoError(t, natCfg.RemoveHairpinInterface(3))
+	require.Equal(t, []*nat44_ed.Nat44InterfaceAddDelFeature{
+		feature(false, 3, nat_types.NAT_IS_OUTSIDE),
+		feature(false, 3, nat_types.NAT_IS_INSIDE),
+	}, recorded[*nat44_ed.Nat44InterfaceAddDelFeature](conn)[2:], "应先移除outside特性再移除inside特性")
+}
+
+func TestConfigureHairpinInterface_RollbackOnOutsideFailure(t *testing.T) {
+	conn := &fakeConn{fail: func(req api.Message) error {
+		if r, ok := req.(*nat44_ed.Nat44InterfaceAddDelFeature); ok && r.IsAdd && r.Flags == nat_types.NAT_IS_OUTSIDE {
+			return errors.New("outside feature rejected")
+		}
+		return nil
+	}}
+	natCfg := vpp.NewNATConfigurator(conn)
+
+	require.Error(t, natCfg.ConfigureHairpinInterface(3))
+	require.Equal(t, []*nat44_ed.Nat44InterfaceAddDelFeature{
+		feature(true, 3, nat_types.NAT_IS_INSIDE),
+		feature(true, 3, nat_types.NAT_IS_OUTSIDE),
+		feature(false, 3, nat_types.NAT_IS_INSIDE),
+	}, recorded[*nat44_ed.Nat44InterfaceAddDelFeature](conn), "outside特性失败时应回滚inside特性")
+}
+
+func TestRemoveHairpinInterface_RemovesInsideAfterOutsideFailure(t *testing.T) {
+	conn := &fakeConn{fail: func(req api.Message) error {
+		if r, ok := req.(*nat44_ed.Nat44InterfaceAddDelFeature); ok && r.Flags == nat_types.NAT_IS_OUTSIDE {
+			return errors.New("outside feature not found")
+		}
+		return nil
+	}}
+	natCfg := vpp.NewNATConfigurator(conn)
+
+	require.Error(t, natCfg.RemoveHairpinInterface(3))
+	require.Equal(t, []*nat44_ed.Nat44InterfaceAddDelFeature{
+		feature(false, 3, nat_types.NAT_IS_OUTSIDE),
+		feature(false, 3, nat_types.NAT_IS_INSIDE),
+	}, recorded[*nat44_ed.Nat44InterfaceAddDelFeature](conn))
+}
+
+func TestHairpin_StaticMappings(t *testing.T) {
+	conn := &fakeConn{}
+	natCfg := vpp.NewNATConfigurator(conn)
+	rules := []config.DNATRule{
+		{ExternalIP: "203.0.113.10", ExternalPort: 80, InternalIP: "10.0.1.100", InternalPort: 8080, Protocol: "tcp"},
+		{ExternalIP: "203.0.113.10", ExternalPort: 53, InternalIP: "10.0.1.53", InternalPort: 53, Protocol: "UDP", SelfTwiceNAT: true},
+	}
+
+	require.NoError(t, natCfg.ConfigureHairpinInterface(3))
+	require.NoError(t, natCfg.ConfigureHairpinInterface(4))
+	require.Empty(t, natCfg.AddDNATRules(rules))
+
+	mappings := recorded[*nat44_ed.Nat44AddDelStaticMappingV2](conn)
+	require.Len(t, mappings, 2, "hairpinning不应额外下发静态映射")
+	for i, m := range mappings {
+		rule := rules[i]
+		require.True(t, m.IsAdd)
+		require.Equal(t, rule.ExternalIP, m.ExternalIPAddress.String())
+		require.Equal(t, rule.ExternalPort, m.ExternalPort)
+		require.Equal(t, rule.InternalIP, m.LocalIPAddress.String())
+		require.Equal(t, rule.InternalPort, m.LocalPort)
+		// 回环流量按外部地址匹配静态映射,映射不能绑定到接口地址
+		require.Equal(t, ^interface_types.InterfaceIndex(0), m.ExternalSwIfIndex)
+		require.Zero(t, m.Flags&nat_types.NAT_IS_OUT2IN_ONLY, "out2in-only映射不处理inside侧发起的回环流量")
+	}
+	require.Equal(t, uint8(6), mappings[0].Protocol)
+	require.Equal(t, uint8(17), mappings[1].Protocol)
+	require.Equal(t, nat_types.NatConfigFlags(0), mappings[0].Flags)
+	require.Equal(t, nat_types.NAT_IS_SELF_TWICE_NAT, mappings[1].Flags)
+
+	require.Equal(t, []*nat44_ed.Nat44InterfaceAddDelFeature{
+		feature(true, 3, nat_types.NAT_IS_INSIDE),
+		feature(true, 3, nat_types.NAT_IS_OUTSIDE),
+		feature(true, 4, nat_types.NAT_IS_INSIDE),
+		feature(true, 4, nat_types.NAT_IS_OUTSIDE),
+	}, recorded[*nat44_ed.Nat44InterfaceAddDelFeature](conn))
+}
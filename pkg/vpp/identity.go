@@ -0,0 +1,157 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"fmt"
+
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/networkservicemesh/govpp/binapi/nat_types"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// exemptionTag VPP身份映射标签,用于识别由本NSE下发的NAT豁免
+const exemptionTag = "nse-nat-exemption"
+
+// ExemptionError 单条NAT豁免的配置错误
+type ExemptionError struct {
+	// Index 豁免在exemptions中的下标
+	Index int
+
+	// Exemption 出错的NAT豁免
+	Exemption config.Exemption
+
+	// Err VPP返回的错误
+	Err error
+}
+
+// Error 实现error接口
+func (e *ExemptionError) Error() string {
+	return fmt.Sprintf("exemptions[%d] (%s): %v", e.Index, e.Exemption, e.Err)
+}
+
+// Unwrap 返回底层错误
+func (e *ExemptionError) Unwrap() error {
+	return e.Err
+}
+
+// AddIdentityMapping 添加NAT豁免
+//
+// 使用Nat44AddDelIdentityMapping将inside地址(或地址+端口)映射为其自身,
+// 匹配的流量保持原地址通过NAT。未设置端口时带NAT_IS_ADDR_ONLY标志,豁免该地址的所有协议。
+//
+// 参数:
+//   - e: NAT豁免
+//
+// 返回:
+//   - error: 地址解析错误、VPP API调用错误或VPP返回的错误码
+//
+// 示例:
+//
+//	err := natCfg.AddIdentityMapping(config.Exemption{IP: "10.0.1.53", Port: 53, Protocol: "udp"})
+func (nc *NATConfigurator) AddIdentityMapping(e config.Exemption) error {
+	return nc.addDelIdentityMapping(e, true)
+}
+
+// DelIdentityMapping 删除NAT豁免
+//
+// 参数:
+//   - e: 与添加时相同的NAT豁免
+//
+// 返回:
+//   - error: 地址解析错误、VPP API调用错误或VPP返回的错误码
+func (nc *NATConfigurator) DelIdentityMapping(e config.Exemption) error {
+	return nc.addDelIdentityMapping(e, false)
+}
+
+// AddExemptions 批量添加NAT豁免
+//
+// 逐条下发,单条失败不影响其余豁免。
+//
+// 参数:
+//   - exemptions: NAT豁免列表
+//
+// 返回:
+//   - []*ExemptionError: 每条失败的豁免对应一个错误,全部成功时为空
+func (nc *NATConfigurator) AddExemptions(exemptions []config.Exemption) []*ExemptionError {
+	var exemptionErrs []*ExemptionError
+	for i, e := range exemptions {
+		if err := nc.AddIdentityMapping(e); err != nil {
+			exemptionErrs = append(exemptionErrs, &ExemptionError{Index: i, Exemption: e, Err: err})
+		}
+	}
+	return exemptionErrs
+}
+
+// DelExemptions 批量删除NAT豁免
+//
+// 参数:
+//   - exemptions: NAT豁免列表
+//
+// 返回:
+//   - []*ExemptionError: 每条失败的豁免对应一个错误,全部成功时为空
+func (nc *NATConfigurator) DelExemptions(exemptions []config.Exemption) []*ExemptionError {
+	var exemptionErrs []*ExemptionError
+	for i, e := range exemptions {
+		if err := nc.DelIdentityMapping(e); err != nil {
+			exemptionErrs = append(exemptionErrs, &ExemptionError{Index: i, Exemption: e, Err: err})
+		}
+	}
+	return exemptionErrs
+}
+
+// addDelIdentityMapping 添加或删除一条身份映射
+func (nc *NATConfigurator) addDelIdentityMapping(e config.Exemption, isAdd bool) error {
+	ip, err := parseIPv4(e.IP)
+	if err != nil {
+		return errors.Wrap(err, "invalid exemption address")
+	}
+
+	req := &nat44_ed.Nat44AddDelIdentityMapping{
+		IsAdd:     isAdd,
+		IPAddress: ip,
+		SwIfIndex: ^interface_types.InterfaceIndex(0), // ~0表示使用IPAddress而非接口地址
+		VrfID:     0,
+		Tag:       exemptionTag,
+	}
+	if e.Port == 0 {
+		req.Flags = nat_types.NAT_IS_ADDR_ONLY
+	} else {
+		if req.Protocol, err = protocolNumber(e.Protocol); err != nil {
+			return err
+		}
+		req.Port = e.Port
+	}
+
+	reply := &nat44_ed.Nat44AddDelIdentityMappingReply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return errors.Wrapf(err, "VPP API Nat44AddDelIdentityMapping failed for %s", e)
+	}
+
+	if reply.Retval != 0 {
+		return fmt.Errorf("VPP returned error code %d when %s identity mapping %s", reply.Retval, addDelVerb(isAdd), e)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp_test
+
+import (
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/networkservicemesh/govpp/binapi/nat_types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
+)
+
+func TestAddExemptions(t *testing.T) {
+	conn := &fakeConn{}
+	natCfg := vpp.NewNATConfigurator(conn)
+
+	require.Empty(t, natCfg.AddExemptions([]config.Exemption{
+		{IP: "10.0.2.10"},
+		{IP: "10.0.2.53", Port: 53, Protocol: "UDP"},
+	}))
+
+	mappings := recorded[*nat44_ed.Nat44AddDelIdentityMapping](conn)
+	require.Len(t, mappings, 2)
+
+	require.True(t, mappings[0].IsAdd)
+	require.Equal(t, "10.0.2.10", mappings[0].IPAddress.String())
+	require.Equal(t, nat_types.NAT_IS_ADDR_ONLY, mappings[0].Flags, "未设置端口时应豁免整个地址")
+	require.Zero(t, mappings[0].Port)
+
+	require.Equal(t, "10.0.2.53", mappings[1].IPAddress.String())
+	require.Zero(t, mappings[1].Flags)
+	require.Equal(t, uint8(17), mappings[1].Protocol)
+	require.Equal(t, uint16(53), mappings[1].Port)
+}
+
+func TestDelExemptions_InvalidAddress(t *testing.T) {
+	conn := &fakeConn{}
+	natCfg := vpp.NewNATConfigurator(conn)
+
+	errs := natCfg.DelExemptions([]config.Exemption{{IP: "fd00::1"}, {IP: "10.0.2.10"}})
+	require.Len(t, errs, 1)
+	require.Equal(t, 0, errs[0].Index)
+
+	mappings := recorded[*nat44_ed.Nat44AddDelIdentityMapping](conn)
+	require.Len(t, mappings, 1, "单条失败不应影响其余豁免")
+	require.False(t, mappings[0].IsAdd)
+}
@@ -0,0 +1,333 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"fmt"
+
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// LBRuleError 单条负载均衡DNAT规则的配置错误
+type LBRuleError struct {
+	// Index 规则在lbRules中的下标
+	Index int
+
+	// Rule 出错的负载均衡DNAT规则
+	Rule config.LBRule
+
+	// Err VPP返回的错误
+	Err error
+}
+
+// Error 实现error接口
+func (e *LBRuleError) Error() string {
+	return fmt.Sprintf("lbRules[%d] (%s, %d backends): %v", e.Index, e.Rule.Endpoint(), len(e.Rule.Backends), e.Err)
+}
+
+// Unwrap 返回底层错误
+func (e *LBRuleError) Unwrap() error {
+	return e.Err
+}
+
+// AddLBStaticMapping 添加负载均衡DNAT静态映射
+//
+// 使用Nat44AddDelLbStaticMapping将 externalIP:externalPort 上的新会话按权重分发到规则的全部后端。
+// 权重对应VPP后端的probability,新会话选择某个后端的概率为其权重占全部权重之和的比例。
+// VPP要求至少2个后端。
+//
+// 参数:
+//   - rule: 负载均衡DNAT规则
+//
+// 返回:
+//   - error: 规则解析错误、VPP API调用错误或VPP返回的错误码
+//
+// 示例:
+//
+//	err := natCfg.AddLBStaticMapping(config.LBRule{
+//	    ExternalIP: "203.0.113.10", ExternalPort: 443, Protocol: "tcp",
+//	    Backends: []config.LBBackend{
+//	        {IP: "10.0.1.10", Port: 8443, Weight: 3},
+//	        {IP: "10.0.1.11", Port: 8443, Weight: 1},
+//	    },
+//	})
+func (nc *NATConfigurator) AddLBStaticMapping(rule config.LBRule) error {
+	return nc.addDelLBStaticMapping(rule, true)
+}
+
+// DelLBStaticMapping 删除负载均衡DNAT静态映射,同时删除全部后端上的会话
+//
+// 参数:
+//   - rule: 与添加时相同的负载均衡DNAT规则
+//
+// 返回:
+//   - error: 规则解析错误、VPP API调用错误或VPP返回的错误码
+func (nc *NATConfigurator) DelLBStaticMapping(rule config.LBRule) error {
+	return nc.addDelLBStaticMapping(rule, false)
+}
+
+// AddLBBackend 向已有的负载均衡静态映射添加一个后端
+//
+// 使用Nat44LbStaticMappingAddDelLocal,只影响之后建立的会话,其他后端上的已有会话不受影响。
+//
+// 参数:
+//   - rule: 负载均衡DNAT规则(只使用外部端点)
+//   - backend: 要添加的后端
+//
+// 返回:
+//   - error: 规则解析错误、VPP API调用错误或VPP返回的错误码
+func (nc *NATConfigurator) AddLBBackend(rule config.LBRule, backend config.LBBackend) error {
+	return nc.addDelLBBackend(rule, backend, true)
+}
+
+// DelLBBackend 从负载均衡静态映射中删除一个后端
+//
+// 只删除该后端上的会话,其他后端上的已有会话不受影响。
+// VPP拒绝删除后剩余不足2个后端的操作。
+//
+// 参数:
+//   - rule: 负载均衡DNAT规则(只使用外部端点)
+//   - backend: 要删除的后端
+//
+// 返回:
+//   - error: 规则解析错误、VPP API调用错误或VPP返回的错误码
+func (nc *NATConfigurator) DelLBBackend(rule config.LBRule, backend config.LBBackend) error {
+	return nc.addDelLBBackend(rule, backend, false)
+}
+
+// ErrLBWeightChangeUnsupported 无法在不重建映射的情况下修改后端权重
+//
+// VPP只能删除并重新添加后端来修改其权重,而删除后端时映射必须至少还剩2个后端。
+var ErrLBWeightChangeUnsupported = errors.New("load-balanced backend weight cannot be changed in place")
+
+// UpdateLBBackends 将负载均衡静态映射的后端从oldRule更新为newRule
+//
+// 两条规则的外部端点必须相同。后端按地址和端口识别,全部使用Nat44LbStaticMappingAddDelLocal逐个更新:
+//   - 先添加新增的后端,再修改权重变化的后端,最后删除移除的后端,映射在更新过程中始终保留至少2个后端
+//   - 权重变化的后端被删除后按新权重重新添加,只清除该后端上的会话
+//   - 其余后端上的已有会话不受影响
+//
+// 映射不会被重建;无法逐个修改权重时(见CheckLBBackendsUpdate)不做任何改动并返回错误。
+//
+// 参数:
+//   - oldRule: 当前生效的规则
+//   - newRule: 新规则
+//
+// 返回:
+//   - error: ErrLBWeightChangeUnsupported,或第一个失败的VPP操作的错误
+func (nc *NATConfigurator) UpdateLBBackends(oldRule, newRule config.LBRule) error {
+	if err := CheckLBBackendsUpdate(oldRule, newRule); err != nil {
+		return err
+	}
+
+	added, reweighted, removed := diffLBBackends(oldRule, newRule)
+	for _, backend := range added {
+		if err := nc.AddLBBackend(newRule, backend); err != nil {
+			return err
+		}
+	}
+	for _, backend := range reweighted {
+		if err := nc.DelLBBackend(oldRule, backend); err != nil {
+			return err
+		}
+		if err := nc.AddLBBackend(newRule, backend); err != nil {
+			return err
+		}
+	}
+	for _, backend := range removed {
+		if err := nc.DelLBBackend(oldRule, backend); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckLBBackendsUpdate 检查能否不重建映射地将后端从oldRule更新为newRule
+//
+// 修改权重需要先删除该后端,此时映射(含新增的后端)必须至少还有3个后端。
+//
+// 返回:
+//   - error: 包装ErrLBWeightChangeUnsupported的错误,可以更新时为nil
+func CheckLBBackendsUpdate(oldRule, newRule config.LBRule) error {
+	added, reweighted, _ := diffLBBackends(oldRule, newRule)
+	if len(reweighted) == 0 {
+		return nil
+	}
+	if n := len(oldRule.Backends) + len(added); n < 3 {
+		return errors.Wrapf(ErrLBWeightChangeUnsupported,
+			"%s has %d backends, changing the weight of %s:%d would leave fewer than 2 while it is re-added",
+			newRule.Endpoint(), n, reweighted[0].IP, reweighted[0].Port)
+	}
+	return nil
+}
+
+// diffLBBackends 按地址和端口比较两条规则的后端,reweighted为权重变化的后端(新权重)
+func diffLBBackends(oldRule, newRule config.LBRule) (added, reweighted, removed []config.LBBackend) {
+	for _, backend := range newRule.Backends {
+		i := oldRule.FindBackend(backend.IP, backend.Port)
+		switch {
+		case i < 0:
+			added = append(added, backend)
+		case oldRule.Backends[i].Weight != backend.Weight:
+			reweighted = append(reweighted, backend)
+		}
+	}
+	for _, backend := range oldRule.Backends {
+		if newRule.FindBackend(backend.IP, backend.Port) < 0 {
+			removed = append(removed, backend)
+		}
+	}
+	return added, reweighted, removed
+}
+
+// AddLBRules 批量添加负载均衡DNAT静态映射
+//
+// 逐条下发规则,单条失败不影响其余规则。
+//
+// 参数:
+//   - rules: 负载均衡DNAT规则列表
+//
+// 返回:
+//   - []*LBRuleError: 每条失败规则对应一个错误,全部成功时为空
+func (nc *NATConfigurator) AddLBRules(rules []config.LBRule) []*LBRuleError {
+	var ruleErrs []*LBRuleError
+	for i, rule := range rules {
+		if err := nc.AddLBStaticMapping(rule); err != nil {
+			ruleErrs = append(ruleErrs, &LBRuleError{Index: i, Rule: rule, Err: err})
+		}
+	}
+	return ruleErrs
+}
+
+// DelLBRules 批量删除负载均衡DNAT静态映射
+//
+// 参数:
+//   - rules: 负载均衡DNAT规则列表
+//
+// 返回:
+//   - []*LBRuleError: 每条失败规则对应一个错误,全部成功时为空
+func (nc *NATConfigurator) DelLBRules(rules []config.LBRule) []*LBRuleError {
+	var ruleErrs []*LBRuleError
+	for i, rule := range rules {
+		if err := nc.DelLBStaticMapping(rule); err != nil {
+			ruleErrs = append(ruleErrs, &LBRuleError{Index: i, Rule: rule, Err: err})
+		}
+	}
+	return ruleErrs
+}
+
+// addDelLBStaticMapping 添加或删除一条负载均衡静态映射
+func (nc *NATConfigurator) addDelLBStaticMapping(rule config.LBRule, isAdd bool) error {
+	externalIP, err := parseIPv4(rule.ExternalIP)
+	if err != nil {
+		return errors.Wrap(err, "invalid externalIP")
+	}
+
+	proto, err := protocolNumber(rule.Protocol)
+	if err != nil {
+		return err
+	}
+
+	locals := make([]nat44_ed.Nat44LbAddrPort, 0, len(rule.Backends))
+	for _, backend := range rule.Backends {
+		local, err := lbAddrPort(backend)
+		if err != nil {
+			return err
+		}
+		locals = append(locals, local)
+	}
+
+	req := &nat44_ed.Nat44AddDelLbStaticMapping{
+		IsAdd:        isAdd,
+		ExternalAddr: externalIP,
+		ExternalPort: rule.ExternalPort,
+		Protocol:     proto,
+		Affinity:     0, // 不启用会话亲和性,每个新会话独立选择后端
+		Tag:          dnatTag,
+		LocalNum:     uint32(len(locals)),
+		Locals:       locals,
+	}
+
+	reply := &nat44_ed.Nat44AddDelLbStaticMappingReply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return errors.Wrapf(err, "VPP API Nat44AddDelLbStaticMapping failed for %s", rule.Endpoint())
+	}
+
+	if reply.Retval != 0 {
+		return fmt.Errorf("VPP returned error code %d when %s load-balanced static mapping %s", reply.Retval, addDelVerb(isAdd), rule.Endpoint())
+	}
+
+	return nil
+}
+
+// addDelLBBackend 添加或删除负载均衡静态映射的一个后端
+func (nc *NATConfigurator) addDelLBBackend(rule config.LBRule, backend config.LBBackend, isAdd bool) error {
+	externalIP, err := parseIPv4(rule.ExternalIP)
+	if err != nil {
+		return errors.Wrap(err, "invalid externalIP")
+	}
+
+	proto, err := protocolNumber(rule.Protocol)
+	if err != nil {
+		return err
+	}
+
+	local, err := lbAddrPort(backend)
+	if err != nil {
+		return err
+	}
+
+	req := &nat44_ed.Nat44LbStaticMappingAddDelLocal{
+		IsAdd:        isAdd,
+		ExternalAddr: externalIP,
+		ExternalPort: rule.ExternalPort,
+		Protocol:     proto,
+		Local:        local,
+	}
+
+	reply := &nat44_ed.Nat44LbStaticMappingAddDelLocalReply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return errors.Wrapf(err, "VPP API Nat44LbStaticMappingAddDelLocal failed for %s backend %s:%d", rule.Endpoint(), backend.IP, backend.Port)
+	}
+
+	if reply.Retval != 0 {
+		return fmt.Errorf("VPP returned error code %d when %s backend %s:%d of %s", reply.Retval, addDelVerb(isAdd), backend.IP, backend.Port, rule.Endpoint())
+	}
+
+	return nil
+}
+
+// lbAddrPort 将后端转换为VPP负载均衡后端
+func lbAddrPort(backend config.LBBackend) (nat44_ed.Nat44LbAddrPort, error) {
+	ip, err := parseIPv4(backend.IP)
+	if err != nil {
+		return nat44_ed.Nat44LbAddrPort{}, errors.Wrap(err, "invalid backend address")
+	}
+	return nat44_ed.Nat44LbAddrPort{
+		Addr:        ip,
+		Port:        backend.Port,
+		Probability: backend.Weight,
+		VrfID:       0,
+	}, nil
+}
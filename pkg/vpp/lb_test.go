@@ -0,0 +1,138 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp_test
+
+import (
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
+)
+
+func lbRule(backends ...config.LBBackend) config.LBRule {
+	return config.LBRule{ExternalIP: "203.0.113.10", ExternalPort: 443, Protocol: "tcp", Backends: backends}
+}
+
+func TestAddLBStaticMapping(t *testing.T) {
+	conn := &fakeConn{}
+	natCfg := vpp.NewNATConfigurator(conn)
+
+	require.NoError(t, natCfg.AddLBStaticMapping(lbRule(
+		config.LBBackend{IP: "10.0.1.10", Port: 8443, Weight: 3},
+		config.LBBackend{IP: "10.0.1.11", Port: 8443, Weight: 1},
+	)))
+
+	mappings := recorded[*nat44_ed.Nat44AddDelLbStaticMapping](conn)
+	require.Len(t, mappings, 1)
+	m := mappings[0]
+	require.True(t, m.IsAdd)
+	require.Equal(t, "203.0.113.10", m.ExternalAddr.String())
+	require.Equal(t, uint16(443), m.ExternalPort)
+	require.Equal(t, uint8(6), m.Protocol)
+	require.Equal(t, uint32(2), m.LocalNum)
+	require.Equal(t, "10.0.1.10", m.Locals[0].Addr.String())
+	require.Equal(t, uint8(3), m.Locals[0].Probability)
+	require.Equal(t, uint8(1), m.Locals[1].Probability)
+}
+
+func TestUpdateLBBackends_KeepsOtherBackends(t *testing.T) {
+	conn := &fakeConn{}
+	natCfg := vpp.NewNATConfigurator(conn)
+	a := config.LBBackend{IP: "10.0.1.10", Port: 8443, Weight: 1}
+	b := config.LBBackend{IP: "10.0.1.11", Port: 8443, Weight: 1}
+	c := config.LBBackend{IP: "10.0.1.12", Port: 8443, Weight: 1}
+
+	// 替换一个后端: 先添加新后端再删除旧后端,映射始终保留至少2个后端
+	require.NoError(t, natCfg.UpdateLBBackends(lbRule(a, b), lbRule(a, c)))
+
+	mappings := recorded[*nat44_ed.Nat44AddDelLbStaticMapping](conn)
+	locals := recorded[*nat44_ed.Nat44LbStaticMappingAddDelLocal](conn)
+	require.Empty(t, mappings, "后端增删不应重建映射")
+	require.Len(t, locals, 2)
+	require.True(t, locals[0].IsAdd)
+	require.Equal(t, "10.0.1.12", locals[0].Local.Addr.String())
+	require.False(t, locals[1].IsAdd)
+	require.Equal(t, "10.0.1.11", locals[1].Local.Addr.String())
+	for _, local := range locals {
+		require.Equal(t, "203.0.113.10", local.ExternalAddr.String())
+		require.Equal(t, uint16(443), local.ExternalPort)
+	}
+}
+
+func TestUpdateLBBackends_WeightChangeReaddsOnlyThatBackend(t *testing.T) {
+	conn := &fakeConn{}
+	natCfg := vpp.NewNATConfigurator(conn)
+	a := config.LBBackend{IP: "10.0.1.10", Port: 8443, Weight: 1}
+	b := config.LBBackend{IP: "10.0.1.11", Port: 8443, Weight: 1}
+	c := config.LBBackend{IP: "10.0.1.12", Port: 8443, Weight: 1}
+	heavier := b
+	heavier.Weight = 5
+
+	require.NoError(t, natCfg.UpdateLBBackends(lbRule(a, b, c), lbRule(a, heavier, c)))
+
+	mappings := recorded[*nat44_ed.Nat44AddDelLbStaticMapping](conn)
+	locals := recorded[*nat44_ed.Nat44LbStaticMappingAddDelLocal](conn)
+	require.Empty(t, mappings, "修改权重不应重建映射")
+	require.Len(t, locals, 2)
+	require.False(t, locals[0].IsAdd)
+	require.Equal(t, "10.0.1.11", locals[0].Local.Addr.String())
+	require.True(t, locals[1].IsAdd)
+	require.Equal(t, "10.0.1.11", locals[1].Local.Addr.String())
+	require.Equal(t, uint8(5), locals[1].Local.Probability)
+}
+
+func TestUpdateLBBackends_WeightChangeWithNewBackend(t *testing.T) {
+	conn := &fakeConn{}
+	natCfg := vpp.NewNATConfigurator(conn)
+	a := config.LBBackend{IP: "10.0.1.10", Port: 8443, Weight: 1}
+	b := config.LBBackend{IP: "10.0.1.11", Port: 8443, Weight: 1}
+	c := config.LBBackend{IP: "10.0.1.12", Port: 8443, Weight: 1}
+	heavier := b
+	heavier.Weight = 5
+
+	// 先添加新后端,删除权重变化的后端时映射仍有至少2个后端
+	require.NoError(t, natCfg.UpdateLBBackends(lbRule(a, b), lbRule(a, heavier, c)))
+
+	locals := recorded[*nat44_ed.Nat44LbStaticMappingAddDelLocal](conn)
+	require.Len(t, locals, 3)
+	require.True(t, locals[0].IsAdd)
+	require.Equal(t, "10.0.1.12", locals[0].Local.Addr.String())
+	require.False(t, locals[1].IsAdd)
+	require.True(t, locals[2].IsAdd)
+	require.Equal(t, uint8(5), locals[2].Local.Probability)
+}
+
+func TestUpdateLBBackends_RejectsWeightChangeOnTwoBackends(t *testing.T) {
+	conn := &fakeConn{}
+	natCfg := vpp.NewNATConfigurator(conn)
+	a := config.LBBackend{IP: "10.0.1.10", Port: 8443, Weight: 1}
+	b := config.LBBackend{IP: "10.0.1.11", Port: 8443, Weight: 1}
+	heavier := b
+	heavier.Weight = 5
+
+	err := natCfg.UpdateLBBackends(lbRule(a, b), lbRule(a, heavier))
+	require.ErrorIs(t, err, vpp.ErrLBWeightChangeUnsupported)
+	require.ErrorIs(t, vpp.CheckLBBackendsUpdate(lbRule(a, b), lbRule(a, heavier)), vpp.ErrLBWeightChangeUnsupported)
+	require.Empty(t, conn.reqs, "无法修改权重时不应改动VPP")
+}
@@ -30,6 +30,8 @@ import (
 	"github.com/networkservicemesh/govpp/binapi/nat_types"
 	"github.com/pkg/errors"
 	"go.fd.io/govpp/api"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
 )
 
 // NATConfigurator VPP NAT44 配置器
@@ -132,8 +134,8 @@ func (nc *NATConfigurator) ConfigureOutsideInterface(swIfIndex uint32) error {
 
 // AddNATAddressPool 添加SNAT地址池
 //
-// 配置SNAT使用的外部IP地址池。支持单个IP或IP范围。
-// 注意:端口范围不通过此函数配置,需单独使用ConfigurePortRange()。
+// 配置SNAT使用的外部IP地址池(单个IP)。地址范围请使用AddAddressRange()。
+// 注意:nat44-ed没有端口分配范围设置,动态SNAT始终使用1024-65535端口。
 //
 // 参数:
 //   - natIP: SNAT外部IP地址(IPv4格式字符串,如"203.0.113.10")
@@ -148,83 +150,97 @@ func (nc *NATConfigurator) ConfigureOutsideInterface(swIfIndex uint32) error {
 //	    log.Fatalf("添加NAT地址池失败: %v", err)
 //	}
 func (nc *NATConfigurator) AddNATAddressPool(natIP string) error {
-	// 解析NAT IP地址
-	ip := net.ParseIP(natIP)
+	ip := net.ParseIP(natIP).To4()
 	if ip == nil {
-		return fmt.Errorf("invalid IP address format: %s", natIP)
+		return fmt.Errorf("NAT IP must be IPv4 address: %s", natIP)
 	}
+	return nc.AddAddressRange(config.AddressRange{First: ip, Last: ip})
+}
 
-	// 确保是IPv4地址
-	ipv4 := ip.To4()
-	if ipv4 == nil {
-		return fmt.Errorf("NAT IP must be IPv4 address: %s", natIP)
+// AddAddressRange 添加SNAT地址范围
+//
+// 直接映射到Nat44AddDelAddressRange,一次添加 First 到 Last(含)的全部地址。
+//
+// 参数:
+//   - r: 地址范围(通常来自config.ParseAddressRange或NATConfig.PoolRanges)
+//
+// 返回:
+//   - error: IP地址解析错误、VPP API调用错误或VPP返回的错误码
+//
+// 示例:
+//
+//	r, _ := config.ParseAddressRange("203.0.113.10-203.0.113.20")
+//	if err := natCfg.AddAddressRange(r); err != nil {
+//	    log.Errorf("添加NAT地址范围失败: %v", err)
+//	}
+func (nc *NATConfigurator) AddAddressRange(r config.AddressRange) error {
+	return nc.addDelAddressRange(r, true, 0)
+}
+
+// AddTwiceNATAddressRange 添加twice-NAT地址范围
+//
+// 与AddAddressRange相同,但地址带NAT_IS_TWICE_NAT标志,
+// 只用于启用twiceNAT/selfTwiceNAT的静态映射的源地址转换,不参与普通SNAT。
+//
+// 参数:
+//   - r: 地址范围(通常来自NATConfig.TwiceNATRanges)
+//
+// 返回:
+//   - error: IP地址解析错误、VPP API调用错误或VPP返回的错误码
+func (nc *NATConfigurator) AddTwiceNATAddressRange(r config.AddressRange) error {
+	return nc.addDelAddressRange(r, true, nat_types.NAT_IS_TWICE_NAT)
+}
+
+// addDelAddressRange 添加或删除SNAT地址范围,flags为0或NAT_IS_TWICE_NAT
+func (nc *NATConfigurator) addDelAddressRange(r config.AddressRange, isAdd bool, flags nat_types.NatConfigFlags) error {
+	first, err := parseIPv4(r.First.String())
+	if err != nil {
+		return errors.Wrap(err, "invalid first address")
 	}
 
-	// 转换为VPP IP4Address类型
-	var vppIP ip_types.IP4Address
-	copy(vppIP[:], ipv4)
+	last, err := parseIPv4(r.Last.String())
+	if err != nil {
+		return errors.Wrap(err, "invalid last address")
+	}
 
 	req := &nat44_ed.Nat44AddDelAddressRange{
-		IsAdd:          true,   // 添加地址池
-		FirstIPAddress: vppIP,  // 地址池起始IP
-		LastIPAddress:  vppIP,  // 地址池结束IP(单IP时相同)
-		VrfID:          0,      // VRF ID(默认0)
-		Flags:          0,      // 标志位(0=默认行为)
+		IsAdd:          isAdd,   // 添加/删除地址池
+		FirstIPAddress: first,   // 地址池起始IP
+		LastIPAddress:  last,    // 地址池结束IP(单IP时相同)
+		VrfID:          r.VrfID, // 租户VRF ID(默认0)
+		Flags:          flags,   // 标志位(0=普通SNAT地址,NAT_IS_TWICE_NAT=twice-NAT地址)
 	}
 
 	reply := &nat44_ed.Nat44AddDelAddressRangeReply{}
 	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
-		return errors.Wrapf(err, "VPP API Nat44AddDelAddressRange failed for IP %s", natIP)
+		return errors.Wrapf(err, "VPP API Nat44AddDelAddressRange failed for %s", r)
 	}
 
 	if reply.Retval != 0 {
-		return fmt.Errorf("VPP returned error code %d when adding NAT address pool %s", reply.Retval, natIP)
+		pool := "NAT address pool"
+		if flags&nat_types.NAT_IS_TWICE_NAT != 0 {
+			pool = "twice-NAT address pool"
+		}
+		return fmt.Errorf("VPP returned error code %d when %s %s %s", reply.Retval, addDelVerb(isAdd), pool, r)
 	}
 
 	return nil
 }
 
-// ConfigurePortRange 配置NAT端口分配范围
-//
-// 使用VPP CLI命令配置NAT端口分配算法的端口范围。
-// 此功能为P3优先级,如不配置则使用VPP默认值(1024-65535)。
-//
-// 参数:
-//   - portStart: 起始端口(建议1024以上,避免特权端口)
-//   - portEnd: 结束端口(最大65535)
-//
-// 返回:
-//   - error: VPP CLI命令执行错误
-//
-// 示例:
-//
-//	err := natCfg.ConfigurePortRange(10000, 20000)
-//	if err != nil {
-//	    log.Errorf("配置端口范围失败(可能影响端口分配策略): %v", err)
-//	}
-func (nc *NATConfigurator) ConfigurePortRange(portStart, portEnd uint16) error {
-	// 验证端口范围
-	if portStart == 0 || portStart > 65535 {
-		return fmt.Errorf("invalid portStart: %d (must be 1-65535)", portStart)
-	}
+// parseIPv4 解析IPv4地址字符串并转换为VPP IP4Address类型
+func parseIPv4(ipStr string) (ip_types.IP4Address, error) {
+	var vppIP ip_types.IP4Address
 
-	if portEnd == 0 || portEnd > 65535 {
-		return fmt.Errorf("invalid portEnd: %d (must be 1-65535)", portEnd)
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return vppIP, fmt.Errorf("invalid IP address format: %s", ipStr)
 	}
 
-	if portStart > portEnd {
-		return fmt.Errorf("portStart (%d) must be <= portEnd (%d)", portStart, portEnd)
+	ipv4 := ip.To4()
+	if ipv4 == nil {
+		return vppIP, fmt.Errorf("IP must be IPv4 address: %s", ipStr)
 	}
 
-	// TODO(P3): 实现VPP CLI命令调用
-	// 当前版本：端口范围验证通过，但不实际配置VPP，使用默认值1024-65535
-	// 未来实现：调用 vpe.CliInband API执行 "nat addr-port-assignment-alg port-range <start> - <end>"
-	//
-	// 示例代码(需要导入 "github.com/networkservicemesh/govpp/binapi/vpe"):
-	// cliCmd := fmt.Sprintf("nat addr-port-assignment-alg port-range %d - %d", portStart, portEnd)
-	// req := &vpe.CliInband{Cmd: cliCmd}
-	// reply := &vpe.CliInbandReply{}
-	// return nc.vppCh.SendRequest(req).ReceiveReply(reply)
-
-	return nil
+	copy(vppIP[:], ipv4)
+	return vppIP, nil
 }
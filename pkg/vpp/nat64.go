@@ -0,0 +1,244 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+	"github.com/networkservicemesh/govpp/binapi/nat64"
+	"github.com/networkservicemesh/govpp/binapi/nat_types"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// EnableNAT64 启用VPP nat64插件
+//
+// nat64插件默认未启用,需在下发前缀、地址池和接口特性之前调用。
+// BIB和会话表大小使用VPP默认值。VPP重启后需重新启用。
+//
+// 返回:
+//   - error: VPP API调用错误或VPP返回的错误码(插件已启用时VPP同样返回错误)
+func (nc *NATConfigurator) EnableNAT64() error {
+	return nc.enableDisableNAT64(true)
+}
+
+// DisableNAT64 停用VPP nat64插件,同时清除插件中的全部NAT64状态
+func (nc *NATConfigurator) DisableNAT64() error {
+	return nc.enableDisableNAT64(false)
+}
+
+// enableDisableNAT64 启用或停用nat64插件
+func (nc *NATConfigurator) enableDisableNAT64(enable bool) error {
+	req := &nat64.Nat64PluginEnableDisable{Enable: enable}
+
+	reply := &nat64.Nat64PluginEnableDisableReply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return errors.Wrap(err, "VPP API Nat64PluginEnableDisable failed")
+	}
+
+	if reply.Retval != 0 {
+		verb := "enabling"
+		if !enable {
+			verb = "disabling"
+		}
+		return fmt.Errorf("VPP returned error code %d when %s NAT64 plugin", reply.Retval, verb)
+	}
+
+	return nil
+}
+
+// AddNAT64Prefix 添加NAT64前缀
+//
+// 参数:
+//   - prefix: IPv6前缀(如"64:ff9b::/96")
+//
+// 返回:
+//   - error: 前缀解析错误、VPP API调用错误或VPP返回的错误码
+//
+// 示例:
+//
+//	if err := natCfg.AddNAT64Prefix(config.WellKnownNAT64Prefix); err != nil {
+//	    log.Errorf("添加NAT64前缀失败: %v", err)
+//	}
+func (nc *NATConfigurator) AddNAT64Prefix(prefix string) error {
+	return nc.addDelNAT64Prefix(prefix, true)
+}
+
+// DelNAT64Prefix 删除NAT64前缀
+func (nc *NATConfigurator) DelNAT64Prefix(prefix string) error {
+	return nc.addDelNAT64Prefix(prefix, false)
+}
+
+// addDelNAT64Prefix 添加或删除NAT64前缀
+func (nc *NATConfigurator) addDelNAT64Prefix(prefix string, isAdd bool) error {
+	vppPrefix, err := parseIPv6Prefix(prefix)
+	if err != nil {
+		return err
+	}
+
+	req := &nat64.Nat64AddDelPrefix{
+		Prefix: vppPrefix,
+		VrfID:  0,
+		IsAdd:  isAdd,
+	}
+
+	reply := &nat64.Nat64AddDelPrefixReply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return errors.Wrapf(err, "VPP API Nat64AddDelPrefix failed for %s", prefix)
+	}
+
+	if reply.Retval != 0 {
+		return fmt.Errorf("VPP returned error code %d when %s NAT64 prefix %s", reply.Retval, addDelVerb(isAdd), prefix)
+	}
+
+	return nil
+}
+
+// AddNAT64AddressRange 添加NAT64 IPv4地址范围
+//
+// 参数:
+//   - r: 地址范围(通常来自NAT64Config.Ranges)
+//
+// 返回:
+//   - error: VPP API调用错误或VPP返回的错误码
+func (nc *NATConfigurator) AddNAT64AddressRange(r config.AddressRange) error {
+	return nc.addDelNAT64AddressRange(r, true)
+}
+
+// DelNAT64AddressRange 删除NAT64 IPv4地址范围
+func (nc *NATConfigurator) DelNAT64AddressRange(r config.AddressRange) error {
+	return nc.addDelNAT64AddressRange(r, false)
+}
+
+// addDelNAT64AddressRange 添加或删除NAT64地址范围
+func (nc *NATConfigurator) addDelNAT64AddressRange(r config.AddressRange, isAdd bool) error {
+	first, err := parseIPv4(r.First.String())
+	if err != nil {
+		return errors.Wrap(err, "invalid first address")
+	}
+
+	last, err := parseIPv4(r.Last.String())
+	if err != nil {
+		return errors.Wrap(err, "invalid last address")
+	}
+
+	req := &nat64.Nat64AddDelPoolAddrRange{
+		StartAddr: first,
+		EndAddr:   last,
+		VrfID:     r.VrfID,
+		IsAdd:     isAdd,
+	}
+
+	reply := &nat64.Nat64AddDelPoolAddrRangeReply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return errors.Wrapf(err, "VPP API Nat64AddDelPoolAddrRange failed for %s", r)
+	}
+
+	if reply.Retval != 0 {
+		return fmt.Errorf("VPP returned error code %d when %s NAT64 address pool %s", reply.Retval, addDelVerb(isAdd), r)
+	}
+
+	return nil
+}
+
+// ConfigureNAT64InsideInterface 配置NAT64 inside接口
+//
+// 与ConfigureInsideInterface对应,在IPv6客户端所在的Server侧接口上启用NAT64转换。
+//
+// 参数:
+//   - swIfIndex: VPP接口索引
+//
+// 返回:
+//   - error: VPP API调用错误或VPP返回的错误码
+func (nc *NATConfigurator) ConfigureNAT64InsideInterface(swIfIndex uint32) error {
+	return nc.nat64InterfaceFeature(swIfIndex, nat_types.NAT_IS_INSIDE, true)
+}
+
+// ConfigureNAT64OutsideInterface 配置NAT64 outside接口
+//
+// 与ConfigureOutsideInterface对应,转换后的IPv4流量从Client侧接口发出。
+//
+// 参数:
+//   - swIfIndex: VPP接口索引
+//
+// 返回:
+//   - error: VPP API调用错误或VPP返回的错误码
+func (nc *NATConfigurator) ConfigureNAT64OutsideInterface(swIfIndex uint32) error {
+	return nc.nat64InterfaceFeature(swIfIndex, nat_types.NAT_IS_OUTSIDE, true)
+}
+
+// RemoveNAT64InsideInterface 移除接口上的NAT64 inside特性
+func (nc *NATConfigurator) RemoveNAT64InsideInterface(swIfIndex uint32) error {
+	return nc.nat64InterfaceFeature(swIfIndex, nat_types.NAT_IS_INSIDE, false)
+}
+
+// RemoveNAT64OutsideInterface 移除接口上的NAT64 outside特性
+func (nc *NATConfigurator) RemoveNAT64OutsideInterface(swIfIndex uint32) error {
+	return nc.nat64InterfaceFeature(swIfIndex, nat_types.NAT_IS_OUTSIDE, false)
+}
+
+// nat64InterfaceFeature 添加或移除接口上的NAT64特性
+func (nc *NATConfigurator) nat64InterfaceFeature(swIfIndex uint32, flags nat_types.NatConfigFlags, isAdd bool) error {
+	side := "inside"
+	if flags == nat_types.NAT_IS_OUTSIDE {
+		side = "outside"
+	}
+
+	req := &nat64.Nat64AddDelInterface{
+		IsAdd:     isAdd,
+		Flags:     flags,
+		SwIfIndex: interface_types.InterfaceIndex(swIfIndex),
+	}
+
+	reply := &nat64.Nat64AddDelInterfaceReply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return errors.Wrapf(err, "VPP API Nat64AddDelInterface failed for %s interface %d", side, swIfIndex)
+	}
+
+	if reply.Retval != 0 {
+		return fmt.Errorf("VPP returned error code %d when %s NAT64 %s interface %d", reply.Retval, addDelVerb(isAdd), side, swIfIndex)
+	}
+
+	return nil
+}
+
+// parseIPv6Prefix 解析IPv6前缀字符串并转换为VPP IP6Prefix类型
+func parseIPv6Prefix(prefix string) (ip_types.IP6Prefix, error) {
+	var vppPrefix ip_types.IP6Prefix
+
+	ip, ipNet, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return vppPrefix, fmt.Errorf("invalid IPv6 prefix format: %s", prefix)
+	}
+
+	if ip.To4() != nil {
+		return vppPrefix, fmt.Errorf("prefix must be IPv6: %s", prefix)
+	}
+
+	ones, _ := ipNet.Mask.Size()
+	copy(vppPrefix.Address[:], ipNet.IP.To16())
+	vppPrefix.Len = uint8(ones)
+	return vppPrefix, nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"fmt"
+
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/npt66"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// AddNPTv6Binding 在接口上添加NPTv6前缀映射
+//
+// 使用VPP npt66插件:从该接口发出的IPv6报文,源地址的InsidePrefix替换为OutsidePrefix;
+// 从该接口收到的报文,目的地址的OutsidePrefix替换回InsidePrefix。
+// 因此映射绑定在outside接口(Client侧memif)上。
+//
+// 参数:
+//   - swIfIndex: VPP接口索引
+//   - rule: NPTv6前缀映射
+//
+// 返回:
+//   - error: 前缀解析错误、VPP API调用错误或VPP返回的错误码
+//
+// 示例:
+//
+//	for _, rule := range natConfig.NPTv6 {
+//	    if err := natCfg.AddNPTv6Binding(clientSideIfIndex, rule); err != nil {
+//	        return err
+//	    }
+//	}
+func (nc *NATConfigurator) AddNPTv6Binding(swIfIndex uint32, rule config.NPTv6Rule) error {
+	return nc.addDelNPTv6Binding(swIfIndex, rule, true)
+}
+
+// DelNPTv6Binding 删除接口上的NPTv6前缀映射
+func (nc *NATConfigurator) DelNPTv6Binding(swIfIndex uint32, rule config.NPTv6Rule) error {
+	return nc.addDelNPTv6Binding(swIfIndex, rule, false)
+}
+
+// addDelNPTv6Binding 添加或删除一条NPTv6前缀映射
+func (nc *NATConfigurator) addDelNPTv6Binding(swIfIndex uint32, rule config.NPTv6Rule, isAdd bool) error {
+	internal, err := parseIPv6Prefix(rule.InsidePrefix)
+	if err != nil {
+		return errors.Wrap(err, "invalid insidePrefix")
+	}
+
+	external, err := parseIPv6Prefix(rule.OutsidePrefix)
+	if err != nil {
+		return errors.Wrap(err, "invalid outsidePrefix")
+	}
+
+	req := &npt66.Npt66BindingAddDel{
+		IsAdd:     isAdd,
+		SwIfIndex: interface_types.InterfaceIndex(swIfIndex),
+		Internal:  internal,
+		External:  external,
+	}
+
+	reply := &npt66.Npt66BindingAddDelReply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return errors.Wrapf(err, "VPP API Npt66BindingAddDel failed for %s -> %s on interface %d", rule.InsidePrefix, rule.OutsidePrefix, swIfIndex)
+	}
+
+	if reply.Retval != 0 {
+		return fmt.Errorf("VPP returned error code %d when %s NPTv6 binding %s -> %s on interface %d",
+			reply.Retval, addDelVerb(isAdd), rule.InsidePrefix, rule.OutsidePrefix, swIfIndex)
+	}
+
+	return nil
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// AddressPool 按连接引用计数的NAT地址池管理器
+//
+// 多个NSM连接共享同一个SNAT地址范围时,VPP中只能添加一次。
+// AddressPool记录每个地址范围被哪些连接使用:
+//   - 地址范围第一次被使用时添加到VPP
+//   - 最后一个使用者释放后从VPP删除
+//
+// 所有方法并发安全且幂等:同一连接重复Acquire同一范围、
+// 或重复Release都不会改变VPP状态。
+type AddressPool struct {
+	natConfigurator *NATConfigurator
+
+	mu     sync.Mutex
+	ranges map[string]config.AddressRange // 范围键 → 地址范围
+	users  map[string]map[string]struct{} // 范围键 → 使用该范围的连接ID集合
+	conns  map[string]map[string]struct{} // 连接ID → 该连接使用的范围键集合
+}
+
+// NewAddressPool 创建地址池管理器
+//
+// 参数:
+//   - natConfigurator: 用于添加/删除VPP地址池的NAT配置器
+//
+// 返回:
+//   - *AddressPool: 地址池管理器实例
+//
+// 示例:
+//
+//	pool := vpp.NewAddressPool(natCfg)
+//	ranges, _ := natConfig.PoolRanges()
+//	if err := pool.Acquire(connID, ranges...); err != nil {
+//	    return err
+//	}
+//	defer pool.Release(connID)
+func NewAddressPool(natConfigurator *NATConfigurator) *AddressPool {
+	return &AddressPool{
+		natConfigurator: natConfigurator,
+		ranges:          make(map[string]config.AddressRange),
+		users:           make(map[string]map[string]struct{}),
+		conns:           make(map[string]map[string]struct{}),
+	}
+}
+
+// Acquire 为连接获取NAT地址范围
+//
+// 地址范围尚无使用者时先添加到VPP。任一范围添加失败时,
+// 回滚本次调用新添加的范围,连接不持有任何新引用。
+//
+// 参数:
+//   - connID: NSM连接ID
+//   - ranges: 连接使用的地址范围
+//
+// 返回:
+//   - error: 向VPP添加地址范围失败
+func (p *AddressPool) Acquire(connID string, ranges ...config.AddressRange) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var acquired []string
+	for _, r := range ranges {
+		key := rangeKey(r)
+		users, ok := p.users[key]
+		if ok {
+			if _, held := users[connID]; held {
+				continue
+			}
+		} else {
+			if err := p.natConfigurator.AddAddressRange(r); err != nil {
+				for _, k := range acquired {
+					p.releaseLocked(connID, k)
+				}
+				return errors.Wrapf(err, "failed to add NAT address range %s", key)
+			}
+			users = make(map[string]struct{})
+			p.users[key] = users
+			p.ranges[key] = r
+		}
+
+		users[connID] = struct{}{}
+		if p.conns[connID] == nil {
+			p.conns[connID] = make(map[string]struct{})
+		}
+		p.conns[connID][key] = struct{}{}
+		acquired = append(acquired, key)
+	}
+
+	return nil
+}
+
+// Release 释放连接持有的全部NAT地址范围
+//
+// 地址范围的最后一个使用者释放后从VPP删除该范围。
+// 删除失败时仍移除引用记录,并返回最后一个错误。
+//
+// 参数:
+//   - connID: NSM连接ID
+//
+// 返回:
+//   - error: 从VPP删除地址范围失败
+func (p *AddressPool) Release(connID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var lastErr error
+	for key := range p.conns[connID] {
+		if err := p.releaseLocked(connID, key); err != nil {
+			lastErr = err
+		}
+	}
+	delete(p.conns, connID)
+
+	return lastErr
+}
+
+// Replace 将地址池从oldRanges更新为newRanges
+//
+// 用于配置热更新,只处理发生变化的地址范围:
+//   - 仅在oldRanges中的范围:若已添加到VPP则删除,并清除引用记录
+//   - 仅在newRanges中的范围:若oldRanges中有范围正被使用,
+//     则添加到VPP,并由这些连接共同持有
+//
+// 参数:
+//   - oldRanges: 更新前的地址范围
+//   - newRanges: 更新后的地址范围
+//
+// 返回:
+//   - error: 最后一个VPP添加/删除错误
+func (p *AddressPool) Replace(oldRanges, newRanges []config.AddressRange) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	newKeys := make(map[string]config.AddressRange, len(newRanges))
+	for _, r := range newRanges {
+		newKeys[rangeKey(r)] = r
+	}
+	oldKeys := make(map[string]bool, len(oldRanges))
+	holders := make(map[string]struct{})
+	for _, r := range oldRanges {
+		key := rangeKey(r)
+		oldKeys[key] = true
+		for connID := range p.users[key] {
+			holders[connID] = struct{}{}
+		}
+	}
+
+	var lastErr error
+
+	// 删除不再存在的范围
+	for key := range oldKeys {
+		if _, keep := newKeys[key]; keep {
+			continue
+		}
+		users, ok := p.users[key]
+		if !ok {
+			continue
+		}
+		for connID := range users {
+			delete(p.conns[connID], key)
+		}
+		r := p.ranges[key]
+		delete(p.users, key)
+		delete(p.ranges, key)
+		if err := p.natConfigurator.DelAddressRange(r); err != nil {
+			lastErr = errors.Wrapf(err, "failed to delete NAT address range %s", key)
+		}
+	}
+
+	// 添加新范围,由原地址池的使用者共同持有
+	if len(holders) == 0 {
+		return lastErr
+	}
+	for key, r := range newKeys {
+		if oldKeys[key] {
+			continue
+		}
+		if _, ok := p.users[key]; ok {
+			continue
+		}
+		if err := p.natConfigurator.AddAddressRange(r); err != nil {
+			lastErr = errors.Wrapf(err, "failed to add NAT address range %s", key)
+			continue
+		}
+		users := make(map[string]struct{}, len(holders))
+		for connID := range holders {
+			users[connID] = struct{}{}
+			if p.conns[connID] == nil {
+				p.conns[connID] = make(map[string]struct{})
+			}
+			p.conns[connID][key] = struct{}{}
+		}
+		p.users[key] = users
+		p.ranges[key] = r
+	}
+
+	return lastErr
+}
+
+// Ranges 返回当前已添加到VPP(至少有一个使用者)的地址范围
+func (p *AddressPool) Ranges() []config.AddressRange {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ranges := make([]config.AddressRange, 0, len(p.ranges))
+	for _, r := range p.ranges {
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// Users 返回使用指定地址范围的连接数
+func (p *AddressPool) Users(r config.AddressRange) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.users[rangeKey(r)])
+}
+
+// rangeKey 返回地址范围在池中的唯一键(范围+VRF)
+func rangeKey(r config.AddressRange) string {
+	return fmt.Sprintf("%s@vrf%d", r, r.VrfID)
+}
+
+// releaseLocked 释放连接对单个范围的引用,调用方必须持有锁
+func (p *AddressPool) releaseLocked(connID, key string) error {
+	if conns := p.conns[connID]; conns != nil {
+		delete(conns, key)
+	}
+
+	users, ok := p.users[key]
+	if !ok {
+		return nil
+	}
+	delete(users, connID)
+	if len(users) > 0 {
+		return nil
+	}
+
+	r := p.ranges[key]
+	delete(p.users, key)
+	delete(p.ranges, key)
+	if err := p.natConfigurator.DelAddressRange(r); err != nil {
+		return errors.Wrapf(err, "failed to delete NAT address range %s", key)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/memclnt"
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/stretchr/testify/require"
+	"go.fd.io/govpp/api"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
+)
+
+func mustRange(t *testing.T, entry string) config.AddressRange {
+	r, err := config.ParseAddressRange(entry)
+	require.NoError(t, err)
+	return r
+}
+
+// fakeConn 记录所有请求的VPP连接,fail为nil或返回nil时请求成功
+//
+// dump为dump请求返回details消息,为nil时所有dump返回空结果。
+type fakeConn struct {
+	api.Connection
+
+	mu   sync.Mutex
+	reqs []api.Message
+	fail func(req api.Message) error
+	dump func(req api.Message) []api.Message
+}
+
+func (c *fakeConn) NewStream(ctx context.Context, _ ...api.StreamOption) (api.Stream, error) {
+	return &fakeStream{ctx: ctx, conn: c}, nil
+}
+
+// fakeStream 按发送的dump请求回放details,收到ControlPing时结束
+type fakeStream struct {
+	ctx     context.Context
+	conn    *fakeConn
+	pending []api.Message
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func (s *fakeStream) SendMsg(msg api.Message) error {
+	if _, ok := msg.(*memclnt.ControlPing); ok {
+		s.pending = append(s.pending, &memclnt.ControlPingReply{})
+		return nil
+	}
+	if s.conn.dump != nil {
+		s.pending = append(s.pending, s.conn.dump(msg)...)
+	}
+	return nil
+}
+
+func (s *fakeStream) RecvMsg() (api.Message, error) {
+	if len(s.pending) == 0 {
+		return nil, io.EOF
+	}
+	msg := s.pending[0]
+	s.pending = s.pending[1:]
+	return msg, nil
+}
+
+func (s *fakeStream) Close() error { return nil }
+
+func (c *fakeConn) Invoke(_ context.Context, req, _ api.Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reqs = append(c.reqs, req)
+	if c.fail != nil {
+		return c.fail(req)
+	}
+	return nil
+}
+
+// recorded 返回按顺序记录到的T类型请求
+func recorded[T api.Message](c *fakeConn) []T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var msgs []T
+	for _, req := range c.reqs {
+		if r, ok := req.(T); ok {
+			msgs = append(msgs, r)
+		}
+	}
+	return msgs
+}
+
+// addressRanges 返回记录到的地址池请求(IsAdd → 次数)
+func (c *fakeConn) addressRanges() map[bool]int {
+	counts := make(map[bool]int)
+	for _, r := range recorded[*nat44_ed.Nat44AddDelAddressRange](c) {
+		counts[r.IsAdd]++
+	}
+	return counts
+}
+
+func TestAddressPool_SharedAddress(t *testing.T) {
+	conn := &fakeConn{}
+	pool := vpp.NewAddressPool(vpp.NewNATConfigurator(conn))
+	natIP := mustRange(t, "203.0.113.10")
+
+	require.NoError(t, pool.Acquire("conn-1", natIP))
+	require.NoError(t, pool.Acquire("conn-2", natIP))
+	require.Equal(t, 2, pool.Users(natIP))
+	require.Equal(t, map[bool]int{true: 1}, conn.addressRanges(), "地址只应添加一次")
+
+	require.NoError(t, pool.Release("conn-1"))
+	require.Equal(t, map[bool]int{true: 1}, conn.addressRanges(), "仍有使用者时不应删除地址")
+
+	require.NoError(t, pool.Release("conn-2"))
+	require.Equal(t, map[bool]int{true: 1, false: 1}, conn.addressRanges(), "最后一个使用者释放后应删除地址")
+	require.Equal(t, 0, pool.Users(natIP))
+}
+
+func TestAddressPool_Idempotent(t *testing.T) {
+	conn := &fakeConn{}
+	pool := vpp.NewAddressPool(vpp.NewNATConfigurator(conn))
+	natIP := mustRange(t, "203.0.113.10")
+
+	require.NoError(t, pool.Acquire("conn-1", natIP))
+	require.NoError(t, pool.Acquire("conn-1", natIP))
+	require.Equal(t, 1, pool.Users(natIP))
+
+	require.NoError(t, pool.Release("conn-1"))
+	require.NoError(t, pool.Release("conn-1"))
+	require.Equal(t, map[bool]int{true: 1, false: 1}, conn.addressRanges())
+}
+
+func TestAddressPool_Concurrent(t *testing.T) {
+	conn := &fakeConn{}
+	pool := vpp.NewAddressPool(vpp.NewNATConfigurator(conn))
+	natIP := mustRange(t, "203.0.113.10")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(connID string) {
+			defer wg.Done()
+			require.NoError(t, pool.Acquire(connID, natIP))
+			require.NoError(t, pool.Release(connID))
+		}(fmt.Sprintf("conn-%d", i))
+	}
+	wg.Wait()
+
+	counts := conn.addressRanges()
+	require.Equal(t, counts[true], counts[false], "添加和删除次数应该相等")
+	require.Equal(t, 0, pool.Users(natIP))
+}
+
+func TestAddressPool_MultipleRanges(t *testing.T) {
+	conn := &fakeConn{}
+	pool := vpp.NewAddressPool(vpp.NewNATConfigurator(conn))
+	ranges := []config.AddressRange{
+		mustRange(t, "203.0.113.10-203.0.113.20"),
+		mustRange(t, "198.51.100.0/29"),
+	}
+
+	require.NoError(t, pool.Acquire("conn-1", ranges...))
+	require.NoError(t, pool.Acquire("conn-2", ranges...))
+	require.Equal(t, map[bool]int{true: 2}, conn.addressRanges())
+
+	require.NoError(t, pool.Release("conn-1"))
+	require.NoError(t, pool.Release("conn-2"))
+	require.Equal(t, map[bool]int{true: 2, false: 2}, conn.addressRanges())
+}
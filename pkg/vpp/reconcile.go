@@ -0,0 +1,346 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/networkservicemesh/govpp/binapi/nat_types"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// DefaultReconcileInterval 周期性状态校准的默认间隔
+const DefaultReconcileInterval = 30 * time.Second
+
+// DesiredStateFunc 返回期望的nat44-ed状态(NATConfig + 活动连接)
+type DesiredStateFunc func() *NATState
+
+// Reconciler NATConfig与VPP状态之间的声明式校准器
+//
+// 每次校准读取VPP当前状态(DumpState),与期望状态比较后只下发差异:
+//   - 期望中有而VPP中缺失的地址、接口特性、静态映射(含负载均衡映射)和身份映射被重新添加
+//   - VPP中有而期望中没有的条目被删除
+//
+// 地址池按地址范围比较,缺失或多余的地址合并为连续范围后一次下发。
+// 接口特性只删除本NSE曾经配置过(出现在期望状态中)的接口,其他接口不受管理。
+//
+// 链节点在修改VPP之后才记录连接,校准可能与之并发进行。为避免误删,
+// 期望状态在读取VPP前后各取一次:只添加两次都期望的条目;
+// 只有连续两次校准都判定为多余的条目才会被删除。
+type Reconciler struct {
+	natConfigurator *NATConfigurator
+	desired         DesiredStateFunc
+
+	mu            sync.Mutex
+	stale         map[string]bool       // 上一次校准判定为多余的条目
+	staleAddrs    []config.AddressRange // 上一次校准判定为多余的SNAT地址
+	staleTwiceNAT []config.AddressRange // 上一次校准判定为多余的twice-NAT地址
+	owned         map[string]bool       // 本NSE配置过的接口特性
+}
+
+// NewReconciler 创建状态校准器
+//
+// 参数:
+//   - natConfigurator: NAT配置器
+//   - desired: 期望状态来源
+//
+// 返回:
+//   - *Reconciler: 状态校准器实例
+//
+// 示例:
+//
+//	reconciler := vpp.NewReconciler(natCfg, natEndpoint.DesiredState)
+//	reconciler.Run(ctx, vpp.DefaultReconcileInterval)
+func NewReconciler(natConfigurator *NATConfigurator, desired DesiredStateFunc) *Reconciler {
+	return &Reconciler{
+		natConfigurator: natConfigurator,
+		desired:         desired,
+		stale:           make(map[string]bool),
+		owned:           make(map[string]bool),
+	}
+}
+
+// Run 立即执行一次校准,之后按interval周期执行
+//
+// 函数在后台运行,ctx取消时退出。校准失败只记录日志。
+//
+// 参数:
+//   - ctx: 上下文,控制校准生命周期
+//   - interval: 校准间隔
+func (r *Reconciler) Run(ctx context.Context, interval time.Duration) {
+	logger := log.FromContext(ctx).WithField("vpp", "Reconciler")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := r.Reconcile(ctx); err != nil {
+				logger.Errorf("NAT状态校准失败: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Reconcile 执行一次校准
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - error: 读取VPP状态失败,或部分差异下发失败
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	logger := log.FromContext(ctx).WithField("vpp", "Reconcile")
+
+	desiredBefore := r.desired()
+	current, err := r.natConfigurator.DumpState(ctx)
+	if err != nil {
+		return err
+	}
+	desiredAfter := r.desired()
+
+	before := r.stateItems(desiredBefore)
+	after := r.stateItems(desiredAfter)
+	have := r.stateItems(current)
+	for _, items := range []map[string]stateItem{before, after} {
+		for key, item := range items {
+			if item.iface {
+				r.owned[key] = true
+			}
+		}
+	}
+
+	var failed, added, removed int
+	for key, item := range after {
+		if _, ok := before[key]; !ok {
+			continue
+		}
+		if _, ok := have[key]; ok {
+			continue
+		}
+		if err := item.add(); err != nil {
+			logger.Errorf("恢复 %s 失败: %v", key, err)
+			failed++
+			continue
+		}
+		added++
+	}
+
+	stale := make(map[string]bool)
+	for key, item := range have {
+		if _, ok := before[key]; ok {
+			continue
+		}
+		if _, ok := after[key]; ok {
+			continue
+		}
+		if item.iface && !r.owned[key] {
+			continue
+		}
+		if !r.stale[key] {
+			stale[key] = true
+			continue
+		}
+		if err := item.del(); err != nil {
+			logger.Errorf("删除多余的 %s 失败: %v", key, err)
+			stale[key] = true
+			failed++
+			continue
+		}
+		removed++
+	}
+	r.stale = stale
+
+	// 接口既不再期望也不在VPP中时停止跟踪
+	for key := range r.owned {
+		_, inBefore := before[key]
+		_, inAfter := after[key]
+		_, inHave := have[key]
+		if !inBefore && !inAfter && !inHave {
+			delete(r.owned, key)
+		}
+	}
+
+	nc := r.natConfigurator
+	var addrs, twiceNAT addressDiff
+	r.staleAddrs, addrs = reconcileAddresses(ctx, "address",
+		desiredBefore.addresses(), desiredAfter.addresses(), current.addresses(), r.staleAddrs,
+		nc.AddAddressRange, nc.DelAddressRange)
+	r.staleTwiceNAT, twiceNAT = reconcileAddresses(ctx, "twice-NAT address",
+		desiredBefore.twiceNATAddresses(), desiredAfter.twiceNATAddresses(), current.twiceNATAddresses(), r.staleTwiceNAT,
+		nc.AddTwiceNATAddressRange, nc.DelTwiceNATAddressRange)
+	added += addrs.added + twiceNAT.added
+	removed += addrs.removed + twiceNAT.removed
+	failed += addrs.failed + twiceNAT.failed
+
+	if added > 0 || removed > 0 {
+		logger.Infof("NAT状态已校准: 恢复%d项, 删除%d项", added, removed)
+	}
+	if failed > 0 {
+		return errors.Errorf("%d NAT state changes failed to apply", failed)
+	}
+	return nil
+}
+
+// addressDiff 一类地址池的校准结果(按地址范围计数)
+type addressDiff struct {
+	added, removed, failed int
+}
+
+// reconcileAddresses 按地址范围校准一类地址池
+//
+// 缺失的地址(两次期望都包含而VPP中没有)按连续范围添加;
+// 多余的地址(VPP中有而两次期望都不包含)只删除上一次校准已判定为多余的部分。
+// 返回本次判定为多余、留待下一次校准删除的地址。
+func reconcileAddresses(ctx context.Context, kind string, before, after, have, prevStale []config.AddressRange,
+	add, del func(config.AddressRange) error) ([]config.AddressRange, addressDiff) {
+	logger := log.FromContext(ctx).WithField("vpp", "Reconcile")
+
+	var diff addressDiff
+	missing := config.SubtractRanges(config.IntersectRanges(before, after), have)
+	for _, r := range missing {
+		if err := add(r); err != nil {
+			logger.Errorf("恢复 %s %s 失败: %v", kind, rangeKey(r), err)
+			diff.failed++
+			continue
+		}
+		diff.added++
+	}
+
+	extra := config.SubtractRanges(have, append(append([]config.AddressRange{}, before...), after...))
+	var deleted []config.AddressRange
+	for _, r := range config.IntersectRanges(extra, prevStale) {
+		if err := del(r); err != nil {
+			logger.Errorf("删除多余的 %s %s 失败: %v", kind, rangeKey(r), err)
+			diff.failed++
+			continue
+		}
+		deleted = append(deleted, r)
+		diff.removed++
+	}
+
+	return config.SubtractRanges(extra, deleted), diff
+}
+
+// stateItem 校准的最小单元
+type stateItem struct {
+	add   func() error
+	del   func() error
+	iface bool // 接口特性,只删除本NSE配置过的接口
+}
+
+// stateItems 将地址池以外的状态展开为以唯一键索引的条目
+func (r *Reconciler) stateItems(state *NATState) map[string]stateItem {
+	nc := r.natConfigurator
+	items := make(map[string]stateItem)
+	if state == nil {
+		return items
+	}
+
+	for swIfIndex, flags := range state.Interfaces {
+		if flags&nat_types.NAT_IS_INSIDE != 0 {
+			items[fmt.Sprintf("inside interface %d", swIfIndex)] = stateItem{
+				add:   func() error { return nc.ConfigureInsideInterface(swIfIndex) },
+				del:   func() error { return nc.RemoveInsideInterface(swIfIndex) },
+				iface: true,
+			}
+		}
+		if flags&nat_types.NAT_IS_OUTSIDE != 0 {
+			items[fmt.Sprintf("outside interface %d", swIfIndex)] = stateItem{
+				add:   func() error { return nc.ConfigureOutsideInterface(swIfIndex) },
+				del:   func() error { return nc.RemoveOutsideInterface(swIfIndex) },
+				iface: true,
+			}
+		}
+	}
+
+	for _, rule := range state.StaticMappings {
+		key := "static mapping " + rule.String()
+		if flags := staticMappingFlags(rule); flags != 0 {
+			key += fmt.Sprintf(" flags %d", flags)
+		}
+		items[key] = stateItem{
+			add: func() error { return nc.AddStaticMapping(rule) },
+			del: func() error { return nc.DelStaticMapping(rule) },
+		}
+	}
+
+	for _, rule := range state.LBMappings {
+		items["lb static mapping "+lbMappingKey(rule)] = stateItem{
+			add: func() error { return nc.AddLBStaticMapping(rule) },
+			del: func() error { return nc.DelLBStaticMapping(rule) },
+		}
+	}
+
+	for _, e := range state.Exemptions {
+		items["identity mapping "+e.String()] = stateItem{
+			add: func() error { return nc.AddIdentityMapping(e) },
+			del: func() error { return nc.DelIdentityMapping(e) },
+		}
+	}
+
+	return items
+}
+
+// lbMappingKey 返回负载均衡映射的唯一键,包含按地址排序的后端及其权重
+//
+// 后端或权重不同的映射视为不同条目:多余的旧映射被删除后再添加期望的映射。
+func lbMappingKey(rule config.LBRule) string {
+	backends := make([]string, 0, len(rule.Backends))
+	for _, backend := range rule.Backends {
+		backends = append(backends, fmt.Sprintf("%s:%d*%d", backend.IP, backend.Port, backend.Weight))
+	}
+	sort.Strings(backends)
+	return rule.Endpoint() + " -> " + strings.Join(backends, ",")
+}
+
+// addresses 返回SNAT地址范围,state为nil时返回nil
+func (s *NATState) addresses() []config.AddressRange {
+	if s == nil {
+		return nil
+	}
+	return s.Addresses
+}
+
+// twiceNATAddresses 返回twice-NAT地址范围,state为nil时返回nil
+func (s *NATState) twiceNATAddresses() []config.AddressRange {
+	if s == nil {
+		return nil
+	}
+	return s.TwiceNATAddresses
+}
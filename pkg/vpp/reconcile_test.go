@@ -0,0 +1,130 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/networkservicemesh/govpp/binapi/nat_types"
+	"github.com/stretchr/testify/require"
+	"go.fd.io/govpp/api"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/vpp"
+)
+
+// vppState 供fakeConn dump返回的VPP状态
+type vppState struct {
+	addresses  []string
+	interfaces map[uint32]nat_types.NatConfigFlags
+}
+
+func (s *vppState) dump(req api.Message) []api.Message {
+	var details []api.Message
+	switch req.(type) {
+	case *nat44_ed.Nat44AddressDump:
+		for _, addr := range s.addresses {
+			var ip ip_types.IP4Address
+			copy(ip[:], net.ParseIP(addr).To4())
+			details = append(details, &nat44_ed.Nat44AddressDetails{IPAddress: ip})
+		}
+	case *nat44_ed.Nat44InterfaceDump:
+		for swIfIndex, flags := range s.interfaces {
+			details = append(details, &nat44_ed.Nat44InterfaceDetails{SwIfIndex: interface_types.InterfaceIndex(swIfIndex), Flags: flags})
+		}
+	}
+	return details
+}
+
+// addressRequests 返回记录到的地址池请求,格式为"add|del first-last"
+func addressRequests(c *fakeConn) []string {
+	var reqs []string
+	for _, r := range recorded[*nat44_ed.Nat44AddDelAddressRange](c) {
+		op := "del"
+		if r.IsAdd {
+			op = "add"
+		}
+		reqs = append(reqs, fmt.Sprintf("%s %s-%s", op, r.FirstIPAddress, r.LastIPAddress))
+	}
+	return reqs
+}
+
+func TestReconcile_AddsMissingAddressRanges(t *testing.T) {
+	state := &vppState{addresses: []string{"10.0.0.3", "10.0.0.4"}}
+	conn := &fakeConn{dump: state.dump}
+	desired := &vpp.NATState{Addresses: []config.AddressRange{mustRange(t, "10.0.0.1-10.0.0.8")}}
+	reconciler := vpp.NewReconciler(vpp.NewNATConfigurator(conn), func() *vpp.NATState { return desired })
+
+	require.NoError(t, reconciler.Reconcile(context.Background()))
+	require.Equal(t, []string{"add 10.0.0.1-10.0.0.2", "add 10.0.0.5-10.0.0.8"}, addressRequests(conn),
+		"缺失的地址应按连续范围一次添加")
+}
+
+func TestReconcile_DeletesStaleAddressesOnSecondPass(t *testing.T) {
+	state := &vppState{addresses: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}}
+	conn := &fakeConn{dump: state.dump}
+	desired := &vpp.NATState{Addresses: []config.AddressRange{mustRange(t, "10.0.0.1")}}
+	reconciler := vpp.NewReconciler(vpp.NewNATConfigurator(conn), func() *vpp.NATState { return desired })
+
+	require.NoError(t, reconciler.Reconcile(context.Background()))
+	require.Empty(t, addressRequests(conn), "第一次判定为多余时不应删除")
+
+	require.NoError(t, reconciler.Reconcile(context.Background()))
+	require.Equal(t, []string{"del 10.0.0.2-10.0.0.3"}, addressRequests(conn))
+}
+
+func TestReconcile_KeepsAddressDesiredAgain(t *testing.T) {
+	state := &vppState{addresses: []string{"10.0.0.1", "10.0.0.2"}}
+	conn := &fakeConn{dump: state.dump}
+	desired := &vpp.NATState{}
+	reconciler := vpp.NewReconciler(vpp.NewNATConfigurator(conn), func() *vpp.NATState { return desired })
+
+	require.NoError(t, reconciler.Reconcile(context.Background()))
+	desired = &vpp.NATState{Addresses: []config.AddressRange{mustRange(t, "10.0.0.2")}}
+	require.NoError(t, reconciler.Reconcile(context.Background()))
+	require.Equal(t, []string{"del 10.0.0.1-10.0.0.1"}, addressRequests(conn), "重新期望的地址不应删除")
+}
+
+func TestReconcile_InterfaceOwnership(t *testing.T) {
+	state := &vppState{interfaces: map[uint32]nat_types.NatConfigFlags{
+		3: nat_types.NAT_IS_INSIDE,
+		7: nat_types.NAT_IS_OUTSIDE,
+	}}
+	conn := &fakeConn{dump: state.dump}
+	desired := &vpp.NATState{Interfaces: map[uint32]nat_types.NatConfigFlags{3: nat_types.NAT_IS_INSIDE}}
+	reconciler := vpp.NewReconciler(vpp.NewNATConfigurator(conn), func() *vpp.NATState { return desired })
+	ctx := context.Background()
+
+	require.NoError(t, reconciler.Reconcile(ctx))
+	desired = &vpp.NATState{}
+	require.NoError(t, reconciler.Reconcile(ctx))
+	require.Empty(t, recorded[*nat44_ed.Nat44InterfaceAddDelFeature](conn))
+
+	require.NoError(t, reconciler.Reconcile(ctx))
+	require.Equal(t, []*nat44_ed.Nat44InterfaceAddDelFeature{feature(false, 3, nat_types.NAT_IS_INSIDE)},
+		recorded[*nat44_ed.Nat44InterfaceAddDelFeature](conn), "只应删除本NSE配置过的接口,接口7不受管理")
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"context"
+	"time"
+
+	"github.com/networkservicemesh/govpp/binapi/vpe"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"go.fd.io/govpp/api"
+)
+
+// DefaultRestartCheckInterval VPP重启检测的默认轮询间隔
+const DefaultRestartCheckInterval = 5 * time.Second
+
+// WatchRestart 监测VPP重启或API重连,并在检测到后调用回调
+//
+// VPP重启后其运行时配置(如NAT超时)会恢复为默认值。
+// 本函数周期性查询VPP运行时长(ShowVpeSystemTime),在以下情况下调用onRestart:
+//   - 运行时长变小,说明VPP进程已重启
+//   - 查询失败后再次成功,说明API连接已重新建立
+//
+// 函数在后台运行,ctx取消时退出。
+//
+// 参数:
+//   - ctx: 上下文,控制监测生命周期
+//   - vppConn: VPP API连接
+//   - interval: 轮询间隔
+//   - onRestart: 检测到重启/重连时的回调
+//
+// 示例:
+//
+//	vpp.WatchRestart(ctx, vppConn, vpp.DefaultRestartCheckInterval, func() {
+//	    _ = natCfg.SetTimeouts(natConfig.Timeouts)
+//	})
+func WatchRestart(ctx context.Context, vppConn api.Connection, interval time.Duration, onRestart func()) {
+	logger := log.FromContext(ctx).WithField("vpp", "WatchRestart")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var lastUptime float64
+		disconnected := false
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			reply := &vpe.ShowVpeSystemTimeReply{}
+			if err := vppConn.Invoke(ctx, &vpe.ShowVpeSystemTime{}, reply); err != nil || reply.Retval != 0 {
+				if !disconnected {
+					logger.Warnf("VPP不可达,等待重连: %v", err)
+				}
+				disconnected = true
+				continue
+			}
+
+			uptime := float64(reply.VpeSystemTime)
+			if disconnected || uptime < lastUptime {
+				logger.Infof("检测到VPP重启或重连(运行时长 %.0fs),重新下发配置", uptime)
+				onRestart()
+			}
+			disconnected = false
+			lastUptime = uptime
+		}
+	}()
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/networkservicemesh/govpp/binapi/nat_types"
+	"github.com/pkg/errors"
+)
+
+// Session 一条nat44-ed会话
+type Session struct {
+	// InsideIP 内部地址
+	InsideIP string
+
+	// InsidePort 内部端口(ICMP为标识符)
+	InsidePort uint16
+
+	// OutsideIP 转换后的外部地址
+	OutsideIP string
+
+	// OutsidePort 转换后的外部端口
+	OutsidePort uint16
+
+	// ExtHostIP 对端地址
+	ExtHostIP string
+
+	// ExtHostPort 对端端口
+	ExtHostPort uint16
+
+	// Protocol 协议名("tcp"、"udp"、"icmp"或协议号)
+	Protocol string
+
+	// VrfID 内部地址所在VRF
+	VrfID uint32
+
+	// Static 会话是否由静态映射创建
+	Static bool
+
+	// TotalPkts 会话累计报文数
+	TotalPkts uint32
+
+	// TotalBytes 会话累计字节数
+	TotalBytes uint64
+}
+
+// DumpSessions 读取VPP中的全部nat44-ed会话
+//
+// 先通过Nat44UserDump列出所有NAT用户(内部地址+VRF),
+// 再对每个用户调用Nat44UserSessionV3Dump。已超时但尚未回收的会话不返回。
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - []Session: 会话列表
+//   - error: VPP API调用错误
+func (nc *NATConfigurator) DumpSessions(ctx context.Context) ([]Session, error) {
+	client := nat44_ed.NewServiceClient(nc.vppConn)
+
+	userStream, err := client.Nat44UserDump(ctx, &nat44_ed.Nat44UserDump{})
+	if err != nil {
+		return nil, errors.Wrap(err, "VPP API Nat44UserDump failed")
+	}
+	var users []*nat44_ed.Nat44UserDetails
+	for {
+		details, err := userStream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "VPP API Nat44UserDump failed")
+		}
+		users = append(users, details)
+	}
+
+	var sessions []Session
+	for _, user := range users {
+		stream, err := client.Nat44UserSessionV3Dump(ctx, &nat44_ed.Nat44UserSessionV3Dump{
+			IPAddress: user.IPAddress,
+			VrfID:     user.VrfID,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "VPP API Nat44UserSessionV3Dump failed for %s", user.IPAddress)
+		}
+		for {
+			details, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "VPP API Nat44UserSessionV3Dump failed for %s", user.IPAddress)
+			}
+			if details.IsTimedOut {
+				continue
+			}
+			sessions = append(sessions, Session{
+				InsideIP:    details.InsideIPAddress.String(),
+				InsidePort:  details.InsidePort,
+				OutsideIP:   details.OutsideIPAddress.String(),
+				OutsidePort: details.OutsidePort,
+				ExtHostIP:   details.ExtHostAddress.String(),
+				ExtHostPort: details.ExtHostPort,
+				Protocol:    protocolName(uint8(details.Protocol)),
+				VrfID:       user.VrfID,
+				Static:      details.Flags&nat_types.NAT_IS_STATIC != 0,
+				TotalPkts:   details.TotalPkts,
+				TotalBytes:  details.TotalBytes,
+			})
+		}
+	}
+
+	return sessions, nil
+}
+
+// DelSession 删除一条nat44-ed会话
+//
+// 使用Nat44DelSession,以内部端点和对端端点定位会话(endpoint-dependent模式需要对端信息)。
+//
+// 参数:
+//   - s: 待删除的会话(通常来自DumpSessions)
+//
+// 返回:
+//   - error: 地址解析错误、VPP API调用错误或VPP返回的错误码
+func (nc *NATConfigurator) DelSession(s *Session) error {
+	insideIP, err := parseIPv4(s.InsideIP)
+	if err != nil {
+		return errors.Wrap(err, "invalid inside IP")
+	}
+
+	extHostIP, err := parseIPv4(s.ExtHostIP)
+	if err != nil {
+		return errors.Wrap(err, "invalid external host IP")
+	}
+
+	proto, err := protocolNumber(s.Protocol)
+	if err != nil {
+		return err
+	}
+
+	req := &nat44_ed.Nat44DelSession{
+		IPAddress:      insideIP,
+		Protocol:       proto,
+		Port:           s.InsidePort,
+		VrfID:          s.VrfID,
+		Flags:          nat_types.NAT_IS_INSIDE | nat_types.NAT_IS_EXT_HOST_VALID,
+		ExtHostAddress: extHostIP,
+		ExtHostPort:    s.ExtHostPort,
+	}
+
+	reply := &nat44_ed.Nat44DelSessionReply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return errors.Wrapf(err, "VPP API Nat44DelSession failed for %s %s:%d", s.Protocol, s.InsideIP, s.InsidePort)
+	}
+
+	if reply.Retval != 0 {
+		return fmt.Errorf("VPP returned error code %d when deleting session %s %s:%d", reply.Retval, s.Protocol, s.InsideIP, s.InsidePort)
+	}
+
+	return nil
+}
+
+// TranslationErrors nat44-ed转换失败计数(自VPP启动以来累计)
+type TranslationErrors struct {
+	// OutOfPorts 因端口耗尽失败的次数
+	OutOfPorts uint64
+
+	// MaxSessions 因超过最大会话数失败的次数
+	MaxSessions uint64
+}
+
+// nat44-ed节点错误计数的原因描述
+const (
+	errReasonOutOfPorts  = "out of ports"
+	errReasonMaxSessions = "maximum sessions exceeded"
+)
+
+// DumpTranslationErrors 读取nat44-ed转换失败计数
+//
+// 错误计数位于stats段中,没有对应的二进制API,
+// 因此通过"show errors"读取并汇总所有nat44-ed节点的计数。
+//
+// 返回:
+//   - *TranslationErrors: 各失败原因的累计计数
+//   - error: VPP CLI命令执行错误
+func (nc *NATConfigurator) DumpTranslationErrors() (*TranslationErrors, error) {
+	out, err := nc.runCLI("show errors")
+	if err != nil {
+		return nil, err
+	}
+	return parseTranslationErrors(out), nil
+}
+
+// parseTranslationErrors 从"show errors"输出中汇总nat44-ed节点的失败计数
+//
+// 输出每行格式为"<count> <node> <reason> [severity]"。
+func parseTranslationErrors(out string) *TranslationErrors {
+	errs := &TranslationErrors{}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || !strings.HasPrefix(fields[1], "nat44-ed") {
+			continue
+		}
+		count, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		reason := strings.Join(fields[2:], " ")
+		switch {
+		case strings.Contains(reason, errReasonOutOfPorts):
+			errs.OutOfPorts += count
+		case strings.Contains(reason, errReasonMaxSessions):
+			errs.MaxSessions += count
+		}
+	}
+	return errs
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"fmt"
+
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/networkservicemesh/govpp/binapi/nat_types"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// RemoveInsideInterface 移除接口上的NAT inside特性
+//
+// ConfigureInsideInterface的逆操作,在连接关闭时调用。
+//
+// 参数:
+//   - swIfIndex: VPP接口索引
+//
+// 返回:
+//   - error: VPP API调用错误或VPP返回的错误码
+func (nc *NATConfigurator) RemoveInsideInterface(swIfIndex uint32) error {
+	req := &nat44_ed.Nat44InterfaceAddDelFeature{
+		IsAdd:     false, // 移除NAT特性
+		SwIfIndex: interface_types.InterfaceIndex(swIfIndex),
+		Flags:     nat_types.NAT_IS_INSIDE,
+	}
+
+	reply := &nat44_ed.Nat44InterfaceAddDelFeatureReply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return errors.Wrapf(err, "VPP API Nat44InterfaceAddDelFeature failed for inside interface %d", swIfIndex)
+	}
+
+	if reply.Retval != 0 {
+		return fmt.Errorf("VPP returned error code %d when removing inside interface %d", reply.Retval, swIfIndex)
+	}
+
+	return nil
+}
+
+// RemoveOutsideInterface 移除接口上的NAT outside特性
+//
+// ConfigureOutsideInterface的逆操作,在连接关闭时调用。
+//
+// 参数:
+//   - swIfIndex: VPP接口索引
+//
+// 返回:
+//   - error: VPP API调用错误或VPP返回的错误码
+func (nc *NATConfigurator) RemoveOutsideInterface(swIfIndex uint32) error {
+	req := &nat44_ed.Nat44InterfaceAddDelFeature{
+		IsAdd:     false, // 移除NAT特性
+		SwIfIndex: interface_types.InterfaceIndex(swIfIndex),
+		Flags:     nat_types.NAT_IS_OUTSIDE,
+	}
+
+	reply := &nat44_ed.Nat44InterfaceAddDelFeatureReply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return errors.Wrapf(err, "VPP API Nat44InterfaceAddDelFeature failed for outside interface %d", swIfIndex)
+	}
+
+	if reply.Retval != 0 {
+		return fmt.Errorf("VPP returned error code %d when removing outside interface %d", reply.Retval, swIfIndex)
+	}
+
+	return nil
+}
+
+// DelAddressRange 删除SNAT地址范围
+//
+// AddAddressRange的逆操作,范围必须与添加时一致。
+//
+// 参数:
+//   - r: 地址范围
+//
+// 返回:
+//   - error: VPP API调用错误或VPP返回的错误码
+func (nc *NATConfigurator) DelAddressRange(r config.AddressRange) error {
+	return nc.addDelAddressRange(r, false, 0)
+}
+
+// DelTwiceNATAddressRange 删除twice-NAT地址范围
+//
+// AddTwiceNATAddressRange的逆操作,范围必须与添加时一致。
+func (nc *NATConfigurator) DelTwiceNATAddressRange(r config.AddressRange) error {
+	return nc.addDelAddressRange(r, false, nat_types.NAT_IS_TWICE_NAT)
+}
+
+// ClearUserSessions 清除某个内部地址的全部NAT会话
+//
+// 使用Nat44DelUser删除指定inside地址(NAT用户)在默认FIB中的所有会话。
+//
+// 参数:
+//   - insideIP: 内部客户端IPv4地址
+//
+// 返回:
+//   - error: IP地址解析错误、VPP API调用错误或VPP返回的错误码
+//
+// 示例:
+//
+//	if err := natCfg.ClearUserSessions("10.0.1.5"); err != nil {
+//	    log.Warnf("清除会话失败: %v", err)
+//	}
+func (nc *NATConfigurator) ClearUserSessions(insideIP string) error {
+	vppIP, err := parseIPv4(insideIP)
+	if err != nil {
+		return errors.Wrap(err, "invalid inside IP")
+	}
+
+	req := &nat44_ed.Nat44DelUser{
+		IPAddress: vppIP,
+		FibIndex:  0,
+	}
+
+	reply := &nat44_ed.Nat44DelUserReply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return errors.Wrapf(err, "VPP API Nat44DelUser failed for %s", insideIP)
+	}
+
+	if reply.Retval != 0 {
+		return fmt.Errorf("VPP returned error code %d when clearing sessions of %s", reply.Retval, insideIP)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"fmt"
+
+	"github.com/networkservicemesh/govpp/binapi/nat44_ed"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-nse-nat-vpp/pkg/config"
+)
+
+// SetTimeouts 配置NAT会话超时
+//
+// 使用NatSetTimeouts将TCP已建立/临时、UDP和ICMP会话超时下发到VPP nat44-ed。
+// 该调用是幂等的,可在VPP重连后重复调用。
+//
+// 参数:
+//   - timeouts: NAT超时配置(单位:秒)
+//
+// 返回:
+//   - error: VPP API调用错误或VPP返回的错误码
+//
+// 示例:
+//
+//	err := natCfg.SetTimeouts(natConfig.Timeouts)
+//	if err != nil {
+//	    log.Errorf("配置NAT超时失败: %v", err)
+//	}
+func (nc *NATConfigurator) SetTimeouts(timeouts *config.NATTimeouts) error {
+	if timeouts == nil {
+		return errors.New("NAT timeouts cannot be nil")
+	}
+
+	req := &nat44_ed.NatSetTimeouts{
+		UDP:            timeouts.Udp,
+		TCPEstablished: timeouts.TcpEstablished,
+		TCPTransitory:  timeouts.TcpTransitory,
+		ICMP:           timeouts.Icmp,
+	}
+
+	reply := &nat44_ed.NatSetTimeoutsReply{}
+	if err := nc.vppConn.Invoke(nil, req, reply); err != nil {
+		return errors.Wrap(err, "VPP API NatSetTimeouts failed")
+	}
+
+	if reply.Retval != 0 {
+		return fmt.Errorf("VPP returned error code %d when setting NAT timeouts", reply.Retval)
+	}
+
+	return nil
+}